@@ -438,6 +438,7 @@ func (p *Plugin) ControllerPublishVolume(
 		}
 	case tridentconfig.BlockOnFile:
 		publishInfo["subvolumeMountOptions"] = volumePublishInfo.SubvolumeMountOptions
+		publishInfo["subvolumeUnixPermissions"] = volumePublishInfo.SubvolumeUnixPermissions
 		publishInfo["nfsServerIp"] = volumePublishInfo.NfsServerIP
 		publishInfo["nfsPath"] = volumePublishInfo.NfsPath
 		publishInfo["nfsUniqueID"] = volumePublishInfo.NfsUniqueID
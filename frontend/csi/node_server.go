@@ -1456,6 +1456,7 @@ func (p *Plugin) nodeStageNFSBlockVolume(
 	publishInfo.FilesystemType = req.PublishContext["filesystemType"]
 	publishInfo.SubvolumeMountOptions = utils.SanitizeMountOptions(req.PublishContext["subvolumeMountOptions"],
 		[]string{"ro"})
+	publishInfo.SubvolumeUnixPermissions = req.PublishContext["subvolumeUnixPermissions"]
 
 	// The NFS mount path should be same for all the Subvolumes belonging to the same NFS volumes
 	// thus use NFS volume's Unique ID. This also means the subvolumes from different Virtual Pools,
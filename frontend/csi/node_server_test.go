@@ -12,6 +12,8 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/mitchellh/copystructure"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	mockControllerAPI "github.com/netapp/trident/mocks/mock_frontend/mock_csi/mock_controller_api"
 	mockNodeHelpers "github.com/netapp/trident/mocks/mock_frontend/mock_csi/mock_node_helpers"
@@ -869,3 +871,42 @@ func TestFixNVMeSessions(t *testing.T) {
 	// Cleanup of global objects.
 	publishedNVMeSessions.RemoveNVMeSession(subsystem1.NQN)
 }
+
+func TestGetSubvolumeMountOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		readOnly     bool
+		mountOptions string
+		expected     string
+	}{
+		{"Writable", false, "", "bind"},
+		{"WritableWithOptions", false, "discard", "discard,bind"},
+		{"ReadOnly", true, "", "bind,remount,ro"},
+		{"ReadOnlyWithOptions", true, "discard,bind", "discard,bind,remount,ro"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := getSubvolumeMountOptions(test.readOnly, test.mountOptions)
+			assert.Equal(t, test.expected, result, "subvolume mount options mismatch")
+		})
+	}
+}
+
+func TestNodePublishNFSBlockVolume_MultiNodeReadOnly(t *testing.T) {
+	nodeServer := &Plugin{role: CSINode}
+	req := &csi.NodePublishVolumeRequest{
+		VolumeId: "foo",
+		Readonly: true,
+		VolumeCapability: &csi.VolumeCapability{
+			AccessMode: &csi.VolumeCapability_AccessMode{
+				Mode: csi.VolumeCapability_AccessMode_MULTI_NODE_READER_ONLY,
+			},
+		},
+	}
+
+	_, err := nodeServer.nodePublishNFSBlockVolume(context.Background(), req)
+
+	assert.Error(t, err, "published a block-on-file volume to multiple nodes")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "wrong error code")
+}
@@ -271,6 +271,20 @@ func (mr *MockAzureMockRecorder) ModifyVolume(arg0, arg1, arg2, arg3, arg4, arg5
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyVolume", reflect.TypeOf((*MockAzure)(nil).ModifyVolume), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// ModifyVolumeExportPolicy mocks base method.
+func (m *MockAzure) ModifyVolumeExportPolicy(arg0 context.Context, arg1 *api.FileSystem, arg2 *api.ExportPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ModifyVolumeExportPolicy", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ModifyVolumeExportPolicy indicates an expected call of ModifyVolumeExportPolicy.
+func (mr *MockAzureMockRecorder) ModifyVolumeExportPolicy(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ModifyVolumeExportPolicy", reflect.TypeOf((*MockAzure)(nil).ModifyVolumeExportPolicy), arg0, arg1, arg2)
+}
+
 // RandomSubnetForStoragePool mocks base method.
 func (m *MockAzure) RandomSubnetForStoragePool(arg0 context.Context, arg1 storage.Pool) *api.Subnet {
 	m.ctrl.T.Helper()
@@ -478,6 +492,20 @@ func (mr *MockAzureMockRecorder) SubvolumeExistsByID(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubvolumeExistsByID", reflect.TypeOf((*MockAzure)(nil).SubvolumeExistsByID), arg0, arg1)
 }
 
+// SubvolumePages mocks base method.
+func (m *MockAzure) SubvolumePages(arg0 context.Context, arg1 string, arg2 func([]*api.Subvolume) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubvolumePages", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubvolumePages indicates an expected call of SubvolumePages.
+func (mr *MockAzureMockRecorder) SubvolumePages(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubvolumePages", reflect.TypeOf((*MockAzure)(nil).SubvolumePages), arg0, arg1, arg2)
+}
+
 // SubvolumeParentVolume mocks base method.
 func (m *MockAzure) SubvolumeParentVolume(arg0 context.Context, arg1 *storage.VolumeConfig) (*api.FileSystem, error) {
 	m.ctrl.T.Helper()
@@ -176,6 +176,10 @@ type VolumeExternal struct {
 	Pool        string      `json:"pool"`
 	Orphaned    bool        `json:"orphaned"`
 	State       VolumeState `json:"state"`
+	// BackendState is the volume's state as reported by the storage backend, for drivers that report one
+	BackendState string `json:"backendState,omitempty"`
+	// ParentVolume is the backend volume containing this volume, for drivers that nest volumes in another
+	ParentVolume string `json:"parentVolume,omitempty"`
 }
 
 func (v *VolumeExternal) GetCHAPSecretName() string {
@@ -5,21 +5,28 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	netapp "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/netapp/armnetapp/v5"
 	resourcegraph "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
 	features "github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armfeatures"
 	"github.com/cenkalti/backoff/v4"
+	"golang.org/x/net/http/httpproxy"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient"
 
 	. "github.com/netapp/trident/logging"
@@ -37,8 +44,14 @@ const (
 	DefaultSubvolumeSDKTimeout = 15 * time.Second
 	SDKRetryDelay              = 2 * time.Second
 	SDKMaxRetryDelay           = 15 * time.Second
+	DefaultPollInterval        = 3 * time.Second
+	DefaultMaxPollInterval     = 5 * time.Second
 	CorrelationIDHeader        = "X-Ms-Correlation-Request-Id"
 	SubvolumeNameSeparator     = "-file-"
+
+	CloudEnvironmentAzurePublic       = "AzurePublic"
+	CloudEnvironmentAzureUSGovernment = "AzureUSGovernment"
+	CloudEnvironmentAzureChina        = "AzureChina"
 )
 
 var (
@@ -50,19 +63,28 @@ var (
 	subnetIDRegex       = regexp.MustCompile(`^/subscriptions/(?P<subscriptionID>[^/]+)/resourceGroups/(?P<resourceGroup>[^/]+)/providers/(?P<provider>[^/]+)/virtualNetworks/(?P<virtualNetwork>[^/]+)/subnets/(?P<subnet>[^/]+)$`)
 )
 
+// ANF error code returned when a parent volume lacks room for a subvolume
+const insufficientSpaceErrorCode = "InsufficientSpace"
+
 // ClientConfig holds configuration data for the API driver object.
 type ClientConfig struct {
 	// Azure API authentication parameters
 	azclient.AzureAuthConfig
 	SubscriptionID    string `json:"subscriptionId"`
 	Location          string `json:"location"`
+	CloudEnvironment  string `json:"cloudEnvironment"`
 	StorageDriverName string
 	TenantID          string `json:"tenantId"`
 
 	// Options
-	DebugTraceFlags map[string]bool
-	SDKTimeout      time.Duration // Timeout applied to all calls to the Azure SDK
-	MaxCacheAge     time.Duration // The oldest data we should expect in the cached resources
+	DebugTraceFlags     map[string]bool
+	SDKTimeout          time.Duration // Timeout applied to all calls to the Azure SDK
+	MaxCacheAge         time.Duration // The oldest data we should expect in the cached resources
+	MaxSubvolumesListed int           // The most subvolumes read from any listing, or zero for no limit
+	PollInterval        time.Duration // The first interval between subvolume state checks
+	MaxPollInterval     time.Duration // The longest interval between subvolume state checks
+	ProxyURL            string        // The HTTP(S) proxy for all Azure requests, or empty to use HTTPS_PROXY
+	UserAgent           string        // Added to the User-Agent of all Azure requests, if not empty
 }
 
 // AzureClient holds operational Azure SDK objects.
@@ -136,13 +158,31 @@ func NewDriver(config ClientConfig) (Azure, error) {
 		return nil, errors.New("location must be specified in the config")
 	}
 
+	cloudConfig, err := GetCloudConfiguration(config.CloudEnvironment)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := NewProxyTransport(config.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
 	credential, err := GetAzureCredential(config)
 	if err != nil {
 		return nil, err
 	}
 
+	var perCallPolicies []policy.Policy
+	if config.UserAgent != "" {
+		perCallPolicies = append(perCallPolicies, NewUserAgentPolicy(config.UserAgent))
+	}
+
 	clientOptions := &arm.ClientOptions{
 		ClientOptions: policy.ClientOptions{
+			Cloud:           cloudConfig,
+			Transport:       transport,
+			PerCallPolicies: perCallPolicies,
 			Retry: policy.RetryOptions{
 				TryTimeout:    config.SDKTimeout,
 				RetryDelay:    SDKRetryDelay,
@@ -153,6 +193,9 @@ func NewDriver(config ClientConfig) (Azure, error) {
 
 	subvolumeClientOptions := &arm.ClientOptions{
 		ClientOptions: policy.ClientOptions{
+			Cloud:           cloudConfig,
+			Transport:       transport,
+			PerCallPolicies: perCallPolicies,
 			Retry: policy.RetryOptions{
 				MaxRetries:    6, // 30 seconds, assuming hardcoded Retry-After value of 5 seconds
 				TryTimeout:    DefaultSubvolumeSDKTimeout,
@@ -203,7 +246,25 @@ func GetAzureCredential(config ClientConfig) (credential azcore.TokenCredential,
 		TenantID: config.TenantID,
 	}
 
-	authProvider, err := azclient.NewAuthProvider(&armConfig, &config.AzureAuthConfig)
+	cloudConfig, err := GetCloudConfiguration(config.CloudEnvironment)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := NewProxyTransport(config.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authenticate against the authority host of the configured cloud, through the configured proxy
+	setCloud := func(options *policy.ClientOptions) {
+		options.Cloud = cloudConfig
+		if transport != nil {
+			options.Transport = transport
+		}
+	}
+
+	authProvider, err := azclient.NewAuthProvider(&armConfig, &config.AzureAuthConfig, setCloud)
 	if err != nil {
 		return nil, errors.New("error creating azure auth provider: " + err.Error())
 	}
@@ -211,6 +272,72 @@ func GetAzureCredential(config ClientConfig) (credential azcore.TokenCredential,
 	return authProvider.GetAzIdentity(), nil
 }
 
+// NewProxyTransport returns an HTTP client that sends all Azure requests through the specified proxy, except for
+// hosts excluded by the NO_PROXY environment variable.  If no proxy is specified, nil is returned, so the SDK's
+// default transport, which honors the HTTPS_PROXY and NO_PROXY environment variables, is used.
+func NewProxyTransport(proxyURL string) (policy.Transporter, error) {
+	if proxyURL == "" {
+		return nil, nil
+	}
+
+	// The proxy URL may contain credentials, so it is never included in an error
+	proxy, err := url.Parse(proxyURL)
+	if err != nil || (proxy.Scheme != "http" && proxy.Scheme != "https") || proxy.Host == "" {
+		return nil, errors.New("invalid proxy URL; must be an http or https URL")
+	}
+
+	proxyConfig := httpproxy.FromEnvironment()
+	proxyConfig.HTTPProxy = proxy.String()
+	proxyConfig.HTTPSProxy = proxy.String()
+	proxyFunc := proxyConfig.ProxyFunc()
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(request *http.Request) (*url.URL, error) { return proxyFunc(request.URL) }
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// userAgentPolicy adds an identifying string to the User-Agent of each request.
+type userAgentPolicy struct {
+	userAgent string
+}
+
+// NewUserAgentPolicy returns a pipeline policy that appends userAgent to the User-Agent header of each request.
+// The SDK's own telemetry option truncates its application ID to 24 characters, which is too short to carry
+// both the Trident version and the backend name.
+func NewUserAgentPolicy(userAgent string) policy.Policy {
+	return userAgentPolicy{userAgent: userAgent}
+}
+
+// Do appends the user agent to any User-Agent header set by earlier policies, then sends the request on.
+func (p userAgentPolicy) Do(request *policy.Request) (*http.Response, error) {
+	userAgent := p.userAgent
+	if existing := request.Raw().Header.Get("User-Agent"); existing != "" {
+		userAgent = existing + " " + userAgent
+	}
+	request.Raw().Header.Set("User-Agent", userAgent)
+
+	return request.Next()
+}
+
+// GetCloudConfiguration returns the authority host and Resource Manager endpoint of the named Azure cloud
+// environment.  An empty name selects Azure Public.
+func GetCloudConfiguration(cloudEnvironment string) (cloud.Configuration, error) {
+	switch {
+	case cloudEnvironment == "", strings.EqualFold(cloudEnvironment, CloudEnvironmentAzurePublic):
+		return cloud.AzurePublic, nil
+	case strings.EqualFold(cloudEnvironment, CloudEnvironmentAzureUSGovernment):
+		return cloud.AzureGovernment, nil
+	case strings.EqualFold(cloudEnvironment, CloudEnvironmentAzureChina):
+		return cloud.AzureChina, nil
+	default:
+		return cloud.Configuration{}, fmt.Errorf("invalid cloud environment '%s'; must be one of %s, %s, or %s",
+			cloudEnvironment, CloudEnvironmentAzurePublic, CloudEnvironmentAzureUSGovernment,
+			CloudEnvironmentAzureChina)
+	}
+}
+
 // Init runs startup logic after allocating the driver resources.
 func (c Client) Init(ctx context.Context, pools map[string]storage.Pool) error {
 	// Map vpools to backend
@@ -540,6 +667,12 @@ func exportPolicyExport(exportPolicy *ExportPolicy) *netapp.VolumePropertiesExpo
 			Kerberos5PReadWrite: &kerberos5PReadWrite,
 		}
 
+		// Azure grants root access unless told otherwise, so it is only sent when squashing root
+		if rule.RootSquash {
+			hasRootAccess := false
+			anfRule.HasRootAccess = &hasRootAccess
+		}
+
 		anfRules = append(anfRules, &anfRule)
 	}
 
@@ -572,6 +705,7 @@ func exportPolicyImport(anfExportPolicy *netapp.VolumePropertiesExportPolicy) *E
 			Kerberos5IReadWrite: DerefBool(anfRule.Kerberos5IReadWrite),
 			Kerberos5PReadOnly:  DerefBool(anfRule.Kerberos5PReadOnly),
 			Kerberos5PReadWrite: DerefBool(anfRule.Kerberos5PReadWrite),
+			RootSquash:          anfRule.HasRootAccess != nil && !*anfRule.HasRootAccess,
 		}
 
 		rules = append(rules, rule)
@@ -1164,6 +1298,52 @@ func (c Client) ResizeVolume(ctx context.Context, filesystem *FileSystem, newSiz
 	return nil
 }
 
+// ModifyVolumeExportPolicy sends a VolumePatch to replace a volume's export policy.
+func (c Client) ModifyVolumeExportPolicy(
+	ctx context.Context, filesystem *FileSystem, exportPolicy *ExportPolicy,
+) error {
+	logFields := LogFields{
+		"API":    "VolumesClient.BeginUpdate",
+		"volume": filesystem.FullName,
+	}
+
+	patch := netapp.VolumePatch{
+		ID:       &filesystem.ID,
+		Location: &filesystem.Location,
+		Name:     &filesystem.Name,
+		Properties: &netapp.VolumePatchProperties{
+			ExportPolicy: &netapp.VolumePatchPropertiesExportPolicy{
+				Rules: exportPolicyExport(exportPolicy).Rules,
+			},
+		},
+	}
+
+	var rawResponse *http.Response
+	responseCtx := runtime.WithCaptureResponse(ctx, &rawResponse)
+
+	poller, err := c.sdkClient.VolumesClient.BeginUpdate(responseCtx,
+		filesystem.ResourceGroup, filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, patch, nil)
+
+	logFields["correlationID"] = GetCorrelationID(rawResponse)
+
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error modifying volume export policy.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume export policy modify request issued.")
+
+	_, err = poller.PollUntilDone(responseCtx, &runtime.PollUntilDoneOptions{Frequency: 2 * time.Second})
+	if err != nil {
+		Logc(ctx).WithFields(logFields).WithError(err).Error("Error polling for volume export policy modify result.")
+		return err
+	}
+
+	Logc(ctx).WithFields(logFields).Debug("Volume export policy modified.")
+
+	return nil
+}
+
 // DeleteVolume deletes a volume.
 func (c Client) DeleteVolume(ctx context.Context, filesystem *FileSystem) error {
 	logFields := LogFields{
@@ -1515,6 +1695,7 @@ func (c Client) newSubvolumeFromSubvolumeInfo(_ context.Context, subVol *netapp.
 		Type:              DerefString(subVol.Type),
 		ProvisioningState: DerefString(subVol.Properties.ProvisioningState),
 		Size:              DerefInt64(subVol.Properties.Size),
+		ParentPath:        DerefString(subVol.Properties.ParentPath),
 	}
 
 	return &subvolume, nil
@@ -1539,18 +1720,44 @@ func (c Client) updateSubvolumeFromSubvolumeModel(
 		original.Created = *subVolModel.Properties.CreationTimeStamp
 	}
 
+	if subVolModel.Properties.ParentPath != nil {
+		original.ParentPath = *subVolModel.Properties.ParentPath
+	}
+
 	return original, nil
 }
 
 // SubvolumesForVolume returns a list of subvolume on a volume.
 func (c Client) SubvolumesForVolume(ctx context.Context, filesystem *FileSystem) (*[]*Subvolume, error) {
+	var subvolumes []*Subvolume
+
+	err := c.SubvolumePagesForVolume(ctx, filesystem, func(page []*Subvolume) error {
+		subvolumes = append(subvolumes, page...)
+
+		// Stop paging as soon as the limit is exceeded rather than reading the entire listing into memory
+		if err := c.checkSubvolumesListed(len(subvolumes), filesystem.FullName); err != nil {
+			Logc(ctx).WithField("volume", filesystem.FullName).WithError(err).Error("Too many subvolumes.")
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &subvolumes, nil
+}
+
+// SubvolumePagesForVolume passes the subvolumes on a volume to handler one page at a time, so that the whole
+// listing need never be held in memory.  Paging stops at the first error, which is returned.
+func (c Client) SubvolumePagesForVolume(
+	ctx context.Context, filesystem *FileSystem, handler func([]*Subvolume) error,
+) error {
 	logFields := LogFields{
 		"API":    "SubvolumesClient.NewListByVolumePager",
 		"volume": filesystem.FullName,
 	}
 
-	var subvolumes []*Subvolume
-
 	pager := c.sdkClient.SubvolumesClient.NewListByVolumePager(filesystem.ResourceGroup,
 		filesystem.NetAppAccount, filesystem.CapacityPool, filesystem.Name, nil)
 
@@ -1564,22 +1771,47 @@ func (c Client) SubvolumesForVolume(ctx context.Context, filesystem *FileSystem)
 
 		if err != nil {
 			Logc(ctx).WithFields(logFields).Error("Could not iterate subvolumes.")
-			return nil, fmt.Errorf("error iterating subvolumes: %v", err)
+			return fmt.Errorf("error iterating subvolumes: %v", err)
 		}
 
+		page := make([]*Subvolume, 0, len(nextResult.Value))
 		for _, anfSubvolume := range nextResult.Value {
 			subvolume, subvolumeErr := c.newSubvolumeFromSubvolumeInfo(ctx, anfSubvolume)
 			if subvolumeErr != nil {
 				Logc(ctx).WithError(subvolumeErr).Errorf("Internal error creating subvolume.")
-				return nil, subvolumeErr
+				return subvolumeErr
 			}
-			subvolumes = append(subvolumes, subvolume)
+			page = append(page, subvolume)
+		}
+
+		if err = handler(page); err != nil {
+			return err
 		}
 	}
 
 	Logc(ctx).WithFields(logFields).Debug("Read subvolumes from volume.")
 
-	return &subvolumes, nil
+	return nil
+}
+
+// SubvolumePages passes the subvolumes on a file pool volume, named as resource group/account/pool/volume, to
+// handler one page at a time.  Paging stops at the first error, which is returned.
+func (c Client) SubvolumePages(ctx context.Context, fileVolume string, handler func([]*Subvolume) error) error {
+	resourceGroup, netappAccount, cpoolName, volumeName, err := ParseVolumeName(fileVolume)
+	if err != nil {
+		Logc(ctx).WithError(err).Errorf("Error getting volumes path details from %s.", fileVolume)
+		return err
+	}
+
+	fs := &FileSystem{
+		ResourceGroup: resourceGroup,
+		NetAppAccount: netappAccount,
+		CapacityPool:  cpoolName,
+		Name:          volumeName,
+		FullName:      fileVolume,
+	}
+
+	return c.SubvolumePagesForVolume(ctx, fs, handler)
 }
 
 // Subvolumes returns a list of all subvolumes.
@@ -1607,11 +1839,31 @@ func (c Client) Subvolumes(ctx context.Context, fileVolumePools []string) (*[]*S
 		}
 
 		subvolumes = append(subvolumes, *subvolumesList...)
+
+		if err = c.checkSubvolumesListed(len(subvolumes), fileVolume); err != nil {
+			Logc(ctx).WithError(err).Error("Too many subvolumes.")
+			return nil, err
+		}
 	}
 
 	return &subvolumes, nil
 }
 
+// checkSubvolumesListed returns an error if a subvolume listing has grown beyond the configured limit.
+func (c Client) checkSubvolumesListed(count int, volume string) error {
+	return CheckSubvolumesListed(count, c.config.MaxSubvolumesListed, volume)
+}
+
+// CheckSubvolumesListed returns an error if count exceeds limit, which is ignored if zero.
+func CheckSubvolumesListed(count, limit int, volume string) error {
+	if limit > 0 && count > limit {
+		return errors.MaxLimitReachedError(fmt.Sprintf("listing subvolumes of %s exceeded the limit of %d "+
+			"subvolumes; remove unneeded subvolumes from the file pool volumes or raise maxSubvolumesListed",
+			volume, limit))
+	}
+	return nil
+}
+
 // Subvolume uses a volume config record to fetch a subvolume by the most efficient means.
 func (c Client) Subvolume(
 	ctx context.Context, volConfig *storage.VolumeConfig, queryMetadata bool,
@@ -1892,12 +2144,7 @@ func (c Client) WaitForSubvolumeState(
 			"message":   err.Error(),
 		}).Debugf("Waiting for subvolume state.")
 	}
-	stateBackoff := backoff.NewExponentialBackOff()
-	stateBackoff.MaxElapsedTime = maxElapsedTime
-	stateBackoff.MaxInterval = 5 * time.Second
-	stateBackoff.RandomizationFactor = 0.1
-	stateBackoff.InitialInterval = 3 * time.Second
-	stateBackoff.Multiplier = 1.414
+	stateBackoff := c.subvolumeStateBackoff(maxElapsedTime)
 
 	Logc(ctx).WithFields(logFields).Info("Waiting for subvolume state.")
 
@@ -1916,6 +2163,35 @@ func (c Client) WaitForSubvolumeState(
 	return subvolumeState, nil
 }
 
+// subvolumeStateBackoff returns the policy for polling a subvolume's state.  The interval between checks grows
+// exponentially, with jitter, from the configured poll interval up to the configured maximum, which spreads out the
+// polling of many concurrent operations when the Azure API is throttling requests.  Polling stops once
+// maxElapsedTime has passed.
+func (c Client) subvolumeStateBackoff(maxElapsedTime time.Duration) *backoff.ExponentialBackOff {
+	pollInterval := DefaultPollInterval
+	if c.config.PollInterval > 0 {
+		pollInterval = c.config.PollInterval
+	}
+
+	maxPollInterval := DefaultMaxPollInterval
+	if c.config.MaxPollInterval > 0 {
+		maxPollInterval = c.config.MaxPollInterval
+	}
+	if maxPollInterval < pollInterval {
+		maxPollInterval = pollInterval
+	}
+
+	stateBackoff := backoff.NewExponentialBackOff()
+	stateBackoff.MaxElapsedTime = maxElapsedTime
+	stateBackoff.MaxInterval = maxPollInterval
+	stateBackoff.RandomizationFactor = 0.1
+	stateBackoff.InitialInterval = pollInterval
+	stateBackoff.Multiplier = 1.414
+	stateBackoff.Reset()
+
+	return stateBackoff
+}
+
 // CreateSubvolume creates a new subvolume
 func (c Client) CreateSubvolume(ctx context.Context, request *SubvolumeCreateRequest) (*Subvolume, PollerResponse, error) {
 	subvolumeName := request.CreationToken
@@ -2025,7 +2301,7 @@ func (c Client) ResizeSubvolume(ctx context.Context, subvolume *Subvolume, newSi
 	return nil
 }
 
-// DeleteSubvolume deletes a subvolume.
+// DeleteSubvolume deletes a subvolume.  A subvolume that is already gone is not an error, but no poller is returned.
 func (c Client) DeleteSubvolume(ctx context.Context, subvolume *Subvolume) (PollerResponse, error) {
 	logFields := LogFields{
 		"API": "SubvolumesClient.BeginDelete",
@@ -2061,10 +2337,9 @@ func (c Client) DeleteSubvolume(ctx context.Context, subvolume *Subvolume) (Poll
 func (c Client) ValidateFilePoolVolumes(
 	ctx context.Context, filePoolVolumeNames []string,
 ) ([]*FileSystem, error) {
-	// Ensure the length of filePoolVolumeNames is 1
-	if len(filePoolVolumeNames) != 1 {
-		return nil, fmt.Errorf("the config should contain exactly one entry in filePoolVolumes, "+
-			"it has %d entries", len(filePoolVolumeNames))
+	// Ensure filePoolVolumeNames is not empty
+	if len(filePoolVolumeNames) == 0 {
+		return nil, fmt.Errorf("the config should contain at least one entry in filePoolVolumes")
 	}
 
 	var volumes []*FileSystem
@@ -2078,13 +2353,15 @@ func (c Client) ValidateFilePoolVolumes(
 		volume, err := c.VolumeByID(ctx, CreateVolumeID(c.config.SubscriptionID, resourceGroup, netappAccount,
 			cpoolName, volumeName))
 		if err != nil {
+			if errors.IsNotFoundError(err) {
+				return nil, c.filePoolVolumeNotFoundError(filePoolVolumeName, err)
+			}
 			return nil, err
 		}
 
-		if volume.Location != c.config.Location {
-			return nil, fmt.Errorf("filePoolVolumes validation failed; location of the volume filePoolVolumeNames is"+
-				" not %s but %s", c.config.Location,
-				volume.Location)
+		if !isSameLocation(volume.Location, c.config.Location) {
+			return nil, fmt.Errorf("filePoolVolumes validation failed; location of the volume '%s' is"+
+				" not %s but %s", filePoolVolumeName, c.config.Location, volume.Location)
 		}
 
 		if volume.SubvolumesEnabled == false {
@@ -2102,6 +2379,15 @@ func (c Client) ValidateFilePoolVolumes(
 // Miscellaneous utility functions and error types
 // ///////////////////////////////////////////////////////////////////////////////
 
+// isSameLocation compares two Azure locations.  Azure reports locations in their canonical form (i.e. "eastus"),
+// while a backend config may use the display name (i.e. "East US"), so the comparison ignores case and spaces.
+func isSameLocation(location1, location2 string) bool {
+	canonicalLocation := func(location string) string {
+		return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+	}
+	return canonicalLocation(location1) == canonicalLocation(location2)
+}
+
 // IsANFNotFoundError checks whether an error returned from the ANF SDK contains a 404 (Not Found) error.
 func IsANFNotFoundError(err error) bool {
 	if err == nil {
@@ -2132,6 +2418,86 @@ func IsANFTooManyRequestsError(err error) bool {
 	return false
 }
 
+// GetANFRetryAfter returns the delay requested by the Retry-After header of a 429 (Too Many Requests) error returned
+// from the ANF SDK.  The header may give the delay in seconds or as an HTTP date, and Azure's millisecond variants
+// of the header take precedence if present.
+func GetANFRetryAfter(err error) (time.Duration, bool) {
+	if !IsANFTooManyRequestsError(err) {
+		return 0, false
+	}
+
+	header := err.(*azcore.ResponseError).RawResponse.Header
+
+	for _, name := range []string{"retry-after-ms", "x-ms-retry-after-ms"} {
+		if milliseconds, parseErr := strconv.Atoi(header.Get(name)); parseErr == nil && milliseconds > 0 {
+			return time.Duration(milliseconds) * time.Millisecond, true
+		}
+	}
+
+	value := header.Get("Retry-After")
+	if seconds, parseErr := strconv.Atoi(value); parseErr == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, parseErr := http.ParseTime(value); parseErr == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
+		}
+	}
+
+	return 0, false
+}
+
+// IsANFConflictError checks whether an error returned from the ANF SDK contains a 409 (Conflict) error.
+func IsANFConflictError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if detailedErr, ok := err.(*azcore.ResponseError); ok {
+		if detailedErr.RawResponse != nil && detailedErr.RawResponse.StatusCode == http.StatusConflict {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsANFAuthorizationError checks whether an error returned from the ANF SDK means the client could not authenticate
+// or is not authorized, as when its credentials are invalid or expired, or its identity lacks a role assignment.
+func IsANFAuthorizationError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var authErr *azidentity.AuthenticationFailedError
+	if errors.As(err, &authErr) {
+		return true
+	}
+
+	var detailedErr *azcore.ResponseError
+	if errors.As(err, &detailedErr) && detailedErr.RawResponse != nil {
+		statusCode := detailedErr.RawResponse.StatusCode
+		return statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+	}
+
+	return false
+}
+
+// IsANFInsufficientSpaceError checks whether an error returned from the ANF SDK indicates that a subvolume
+// could not be created or resized because its parent volume does not have enough space.
+func IsANFInsufficientSpaceError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var detailedErr *azcore.ResponseError
+	if errors.As(err, &detailedErr) {
+		return strings.EqualFold(detailedErr.ErrorCode, insufficientSpaceErrorCode)
+	}
+
+	return false
+}
+
 // GetCorrelationIDFromError accepts an error returned from the ANF SDK and extracts the correlation
 // header, if present.
 func GetCorrelationIDFromError(err error) (id string) {
@@ -792,6 +792,35 @@ func (c Client) capacityPool(cPoolFullName string) *CapacityPool {
 	return c.sdkClient.AzureResources.CapacityPoolMap[cPoolFullName]
 }
 
+// filePoolVolumeNotFoundError explains why a file pool volume could not be found by naming the first of its NetApp
+// account, capacity pool, and volume that is missing from the discovered resources.  If no resources have been
+// discovered, the original not-found error is returned.
+func (c Client) filePoolVolumeNotFoundError(filePoolVolumeName string, err error) error {
+	if len(c.sdkClient.AzureResources.CapacityPoolMap) == 0 {
+		return err
+	}
+
+	resourceGroup, netappAccount, cPoolName, volumeName, parseErr := ParseVolumeName(filePoolVolumeName)
+	if parseErr != nil {
+		return err
+	}
+
+	naaFullName := CreateNetappAccountFullName(resourceGroup, netappAccount)
+	if _, ok := c.sdkClient.AzureResources.NetAppAccountMap[naaFullName]; !ok {
+		return errors.WrapWithNotFoundError(err, "filePoolVolumes validation failed; netapp account '%s' of "+
+			"volume '%s' not found in location %s", naaFullName, filePoolVolumeName, c.config.Location)
+	}
+
+	cPoolFullName := CreateCapacityPoolFullName(resourceGroup, netappAccount, cPoolName)
+	if c.capacityPool(cPoolFullName) == nil {
+		return errors.WrapWithNotFoundError(err, "filePoolVolumes validation failed; capacity pool '%s' of "+
+			"volume '%s' not found in netapp account '%s'", cPoolFullName, filePoolVolumeName, naaFullName)
+	}
+
+	return errors.WrapWithNotFoundError(err, "filePoolVolumes validation failed; volume '%s' not found in "+
+		"capacity pool '%s'", volumeName, cPoolFullName)
+}
+
 // CapacityPoolsForStoragePools returns all discovered capacity pools matching all known storage pools,
 // regardless of service levels.
 func (c Client) CapacityPoolsForStoragePools(ctx context.Context) []*CapacityPool {
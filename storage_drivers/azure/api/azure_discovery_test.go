@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/netapp/trident/storage"
+	"github.com/netapp/trident/utils/errors"
 )
 
 var ctx = context.TODO()
@@ -644,6 +645,44 @@ func TestCapacityPools(t *testing.T) {
 	assert.ElementsMatch(t, *expected, *actual)
 }
 
+func TestFilePoolVolumeNotFoundError(t *testing.T) {
+	notFoundErr := errors.NotFoundError("volume not found")
+
+	tests := []struct {
+		name            string
+		filePoolVolume  string
+		expectedMessage string
+	}{
+		{"NetAppAccountMissing", "RG1/NA3/CP1/VOL-1", "netapp account 'RG1/NA3'"},
+		{"ResourceGroupMissing", "RG3/NA1/CP1/VOL-1", "netapp account 'RG3/NA1'"},
+		{"CapacityPoolMissing", "RG2/NA2/CP1/VOL-1", "capacity pool 'RG2/NA2/CP1'"},
+		{"VolumeMissing", "RG1/NA1/CP1/VOL-1", "volume 'VOL-1' not found in capacity pool 'RG1/NA1/CP1'"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sdk := getFakeSDK()
+
+			result := sdk.filePoolVolumeNotFoundError(test.filePoolVolume, notFoundErr)
+
+			assert.True(t, errors.IsNotFoundError(result), "expected not found error")
+			assert.Contains(t, result.Error(), test.expectedMessage, "error does not name the missing component")
+		})
+	}
+}
+
+func TestFilePoolVolumeNotFoundError_NothingDiscovered(t *testing.T) {
+	notFoundErr := errors.NotFoundError("volume not found")
+	sdk := &Client{
+		config:    &ClientConfig{Location: "myLocation"},
+		sdkClient: new(AzureClient),
+	}
+
+	result := sdk.filePoolVolumeNotFoundError("RG1/NA1/CP1/VOL-1", notFoundErr)
+
+	assert.Equal(t, notFoundErr, result, "original error not returned")
+}
+
 func TestCapacityPoolsForStoragePools(t *testing.T) {
 	sdk := getFakeSDK()
 	sdk.sdkClient.StoragePoolMap = make(map[string]storage.Pool)
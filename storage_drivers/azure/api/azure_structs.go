@@ -172,6 +172,7 @@ type ExportRule struct {
 	Kerberos5IReadWrite bool
 	Kerberos5PReadOnly  bool
 	Kerberos5PReadWrite bool
+	RootSquash          bool
 }
 
 // MountTarget records details of a discovered Azure volume mount target.
@@ -211,6 +212,7 @@ type Subvolume struct {
 	ProvisioningState string
 	Size              int64
 	Created           time.Time
+	ParentPath        string
 }
 
 // SubvolumeCreateRequest embodies all the details of a subvolume to be created.
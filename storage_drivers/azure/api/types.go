@@ -37,9 +37,11 @@ type Azure interface {
 	CreateVolume(context.Context, *FilesystemCreateRequest) (*FileSystem, error)
 	ModifyVolume(context.Context, *FileSystem, map[string]string, *string, *bool, *ExportRule) error
 	ResizeVolume(context.Context, *FileSystem, int64) error
+	ModifyVolumeExportPolicy(context.Context, *FileSystem, *ExportPolicy) error
 	DeleteVolume(context.Context, *FileSystem) error
 
 	Subvolumes(context.Context, []string) (*[]*Subvolume, error)
+	SubvolumePages(context.Context, string, func([]*Subvolume) error) error
 	Subvolume(context.Context, *storage.VolumeConfig, bool) (*Subvolume, error)
 	SubvolumeExists(context.Context, *storage.VolumeConfig, []string) (bool, *Subvolume, error)
 	SubvolumeByCreationToken(context.Context, string, []string, bool) (*Subvolume, error)
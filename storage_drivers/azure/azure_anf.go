@@ -587,6 +587,7 @@ func (d *NASStorageDriver) initializeAzureSDKClient(
 		},
 		TenantID:          config.TenantID,
 		Location:          config.Location,
+		CloudEnvironment:  config.CloudEnvironment,
 		StorageDriverName: config.StorageDriverName,
 		DebugTraceFlags:   config.DebugTraceFlags,
 		SDKTimeout:        sdkTimeout,
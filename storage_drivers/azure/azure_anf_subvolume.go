@@ -7,14 +7,25 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
 	"os"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
+	"unicode"
 
 	"github.com/RoaringBitmap/roaring"
+	"github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
 	"sigs.k8s.io/cloud-provider-azure/pkg/azclient"
 
 	tridentconfig "github.com/netapp/trident/config"
@@ -36,6 +47,43 @@ const (
 	snapshotNameSeparator = "--"
 	pvcPrefix             = "pvc-"
 	tempCopySuffix        = "-og"
+
+	defaultMaxStoragePrefixLength  = 10
+	maxSubvolumeNameLength         = 40
+	maxSubvolumeSnapshotNameLength = 45
+	maxCreationTokenLength         = 64
+	snapshotSuffixLength           = 5
+	hashedSnapshotSuffixLength     = 8
+
+	// The default backend name is the driver name plus a random suffix, and is kept short enough to be used as a
+	// Kubernetes label value
+	defaultBackendNameSuffixLength = 6
+	maxDefaultBackendNameLength    = 63
+
+	nfsPort                 = "2049"
+	mountTargetProbeTimeout = 5 * time.Second
+	imdsTimeout             = 5 * time.Second
+
+	defaultAutoExportCIDR = "0.0.0.0/0"
+
+	securityFlavorSys   = "sys"
+	securityFlavorKrb5  = "krb5"
+	securityFlavorKrb5I = "krb5i"
+	securityFlavorKrb5P = "krb5p"
+
+	defaultParentVolumeLookupTimeout = 10 * time.Second
+	parentVolumeLookupInterval       = 250 * time.Millisecond
+	maxParentVolumeCacheAge          = 30 * time.Second
+
+	defaultClockSkewThreshold = 5 * time.Minute
+
+	defaultDeleteRetryCount = 2
+
+	defaultSnapshotListingWorkers = 1
+
+	parentVolumeSizeIncrementBytes = int64(1073741824) // 1 GiB
+
+	StateReasonHealthCheckFailed = "Azure NetApp Files health check failed"
 )
 
 var (
@@ -43,7 +91,22 @@ var (
 	subvolumeSnapshotNameRegex  = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{0,44}$`)
 	subvolumeCreationTokenRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]{0,63}$`)
 
-	pollerResponseCache = make(map[PollerKey]api.PollerResponse)
+	supportedFileSystemTypes = []string{tridentconfig.FsExt3, tridentconfig.FsExt4, tridentconfig.FsXfs}
+	supportedSecurityFlavors = []string{securityFlavorSys, securityFlavorKrb5, securityFlavorKrb5I, securityFlavorKrb5P}
+	kerberosSecurityFlavors  = []string{securityFlavorKrb5, securityFlavorKrb5I, securityFlavorKrb5P}
+
+	// mountTargetDialer is used to probe mount target reachability; unit tests may replace it.
+	mountTargetDialer = net.DialTimeout
+
+	// imdsSubscriptionURL returns the subscription of the VM Trident runs on; unit tests may replace it.
+	imdsSubscriptionURL = "http://169.254.169.254/metadata/instance/compute/subscriptionId?" +
+		"api-version=2021-02-01&format=text"
+
+	// deleteRetryInterval is the initial backoff between subvolume delete attempts; unit tests may replace it.
+	deleteRetryInterval = time.Second
+
+	// maxRetryAfterDelay caps how long a throttled request waits when Azure asks for a longer delay
+	maxRetryAfterDelay = time.Minute
 )
 
 type Operation int64
@@ -55,13 +118,110 @@ const (
 	Restore
 )
 
+// String returns the name of the operation, as used in metrics.
+func (o Operation) String() string {
+	switch o {
+	case Create:
+		return "create"
+	case Delete:
+		return "delete"
+	case Update:
+		return "update"
+	case Restore:
+		return "restore"
+	default:
+		return "unknown"
+	}
+}
+
 type PollerKey struct {
 	ID        string
 	Operation Operation
 }
 
-// key is subvolume ID and value can be snapshot ID or empty
-var subvolumesToDelete map[string]string
+// pollerCache is a concurrency-safe map of in-flight subvolume operations to their pollers.
+type pollerCache struct {
+	pollers map[PollerKey]api.PollerResponse
+	m       *sync.RWMutex
+}
+
+func newPollerCache() *pollerCache {
+	return &pollerCache{
+		pollers: make(map[PollerKey]api.PollerResponse),
+		m:       &sync.RWMutex{},
+	}
+}
+
+// Get returns the poller saved for the specified key, and whether one was found.
+func (pc *pollerCache) Get(key PollerKey) (api.PollerResponse, bool) {
+	pc.m.RLock()
+	defer pc.m.RUnlock()
+
+	poller, ok := pc.pollers[key]
+	return poller, ok
+}
+
+// Set adds or replaces the poller saved for the specified key.
+func (pc *pollerCache) Set(key PollerKey, poller api.PollerResponse) {
+	pc.m.Lock()
+	defer pc.m.Unlock()
+
+	pc.pollers[key] = poller
+}
+
+// Delete removes the poller saved for the specified key.  Does nothing if the key does not exist.
+func (pc *pollerCache) Delete(key PollerKey) {
+	pc.m.Lock()
+	defer pc.m.Unlock()
+
+	delete(pc.pollers, key)
+}
+
+// Clear removes all saved pollers.
+func (pc *pollerCache) Clear() {
+	pc.m.Lock()
+	defer pc.m.Unlock()
+
+	pc.pollers = make(map[PollerKey]api.PollerResponse)
+}
+
+// volumeRateLimiters is a concurrency-safe set of token-bucket rate limiters, one per parent volume, so a burst of
+// operations against one parent volume is smoothed without slowing operations against any other.
+type volumeRateLimiters struct {
+	limit    rate.Limit
+	limiters map[string]*rate.Limiter
+	m        *sync.Mutex
+}
+
+// newVolumeRateLimiters returns limiters allowing opsPerSecond operations per parent volume; zero is unlimited.
+func newVolumeRateLimiters(opsPerSecond int) *volumeRateLimiters {
+	return &volumeRateLimiters{
+		limit:    rate.Limit(opsPerSecond),
+		limiters: make(map[string]*rate.Limiter),
+		m:        &sync.Mutex{},
+	}
+}
+
+// Wait blocks until an operation against the specified parent volume is allowed, or the context is done.
+func (vl *volumeRateLimiters) Wait(ctx context.Context, volumeFullName string) error {
+	if vl == nil || vl.limit == 0 {
+		return nil
+	}
+
+	vl.m.Lock()
+	limiter, ok := vl.limiters[volumeFullName]
+	if !ok {
+		limiter = rate.NewLimiter(vl.limit, 1)
+		vl.limiters[volumeFullName] = limiter
+	}
+	vl.m.Unlock()
+
+	if err := limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("operation on parent volume %s was not started before the rate limit allowed; %v",
+			volumeFullName, err)
+	}
+	return nil
+}
 
 type SubvolumeHelper struct {
 	Config         drivers.AzureNASStorageDriverConfig
@@ -73,7 +233,8 @@ func NewFileHelper(config drivers.AzureNASStorageDriverConfig, context tridentco
 	subvolumeHelper := SubvolumeHelper{}
 	subvolumeHelper.Config = config
 	subvolumeHelper.Context = context
-	regexSnapshotName := fmt.Sprintf("(?m)%v-(.+?)%v(.+)", *subvolumeHelper.Config.StoragePrefix, snapshotNameSeparator)
+	regexSnapshotName := fmt.Sprintf("(?m)%v(.+?)%v(.+)",
+		storagePrefixWithSeparator(*subvolumeHelper.Config.StoragePrefix), snapshotNameSeparator)
 	subvolumeHelper.SnapshotRegexp = regexp.MustCompile(regexSnapshotName)
 
 	return &subvolumeHelper
@@ -81,8 +242,23 @@ func NewFileHelper(config drivers.AzureNASStorageDriverConfig, context tridentco
 
 // volName is expected not to have the storage prefix included
 // parameters: volName=pvc-abc1234-324abc34
-// output: abc12
+// output: abc12, or 8 hex digits hashed from the whole volume name if hashedSnapshotSuffix is set
 func (o *SubvolumeHelper) GetSnapshotSuffix(volName string) string {
+	if o.Config.HashedSnapshotSuffix {
+		return getHashedSnapshotSuffix(volName)
+	}
+	return getShortSnapshotSuffix(volName)
+}
+
+// GetSnapshotSuffixes returns each suffix a snapshot of the volume may have, so snapshots are found whether they
+// were created with the short suffix or the hashed suffix.
+func (o *SubvolumeHelper) GetSnapshotSuffixes(volName string) []string {
+	return []string{getShortSnapshotSuffix(volName), getHashedSnapshotSuffix(volName)}
+}
+
+// getShortSnapshotSuffix returns the legacy snapshot suffix, taken from the start of the PVC UID, which two volumes
+// may share.
+func getShortSnapshotSuffix(volName string) string {
 	var suffix string
 	if strings.HasPrefix(volName, pvcPrefix) && len(volName) > len(pvcPrefix) {
 		uid := strings.Split(volName, pvcPrefix)[1]
@@ -96,14 +272,21 @@ func (o *SubvolumeHelper) GetSnapshotSuffix(volName string) string {
 	return suffix
 }
 
+// getHashedSnapshotSuffix returns a snapshot suffix hashed from the whole volume name, so it is very unlikely to be
+// shared by two volumes.
+func getHashedSnapshotSuffix(volName string) string {
+	hash := sha256.Sum256([]byte(volName))
+	return fmt.Sprintf("%x", hash)[:hashedSnapshotSuffixLength]
+}
+
 // volName is expected not to have the storage prefix included
 // parameters: volName=pvc-abc1234-324abc34 snapName=my-Snapshot
 // output: prefix-my-Snapshot--abc12
 func (o *SubvolumeHelper) GetSnapshotInternalName(volName, snapNameValue string) string {
 	snapName := strings.Replace(snapNameValue, snapshotNameSeparator, "-", -1)
 
-	name := fmt.Sprintf("%v-%v%v%v", *o.Config.StoragePrefix, snapName, snapshotNameSeparator,
-		o.GetSnapshotSuffix(volName))
+	name := fmt.Sprintf("%v%v%v%v", storagePrefixWithSeparator(*o.Config.StoragePrefix), snapName,
+		snapshotNameSeparator, o.GetSnapshotSuffix(volName))
 
 	return name
 }
@@ -112,7 +295,7 @@ func (o *SubvolumeHelper) getSnapshotInternalNameComponents(snapshotInternalName
 	result := o.SnapshotRegexp.FindStringSubmatch(snapshotInternalName)
 	// result [0] is the full string: prefix-mySnap--chars
 	// result [1] is the snapshot name: mySnap
-	// result [2] is chars (unused)
+	// result [2] is chars, either the short suffix or the hashed suffix
 	return result
 }
 
@@ -150,6 +333,52 @@ type NASBlockStorageDriver struct {
 	SDK                 api.Azure
 	helper              *SubvolumeHelper
 	volumeCreateTimeout time.Duration
+	maxSubvolumesListed int
+
+	// how long Destroy, Resize, and CreateSnapshot/DeleteSnapshot wait for the SDK to finish
+	deleteTimeout   time.Duration
+	resizeTimeout   time.Duration
+	snapshotTimeout time.Duration
+
+	// how long CreateFollowup retries transient failures reading a subvolume's parent volume
+	parentVolumeLookupTimeout time.Duration
+
+	// how many times a subvolume delete rejected with a transient error is retried
+	deleteRetryCount int
+
+	// how many goroutines GetSnapshots uses to match a parent volume's subvolumes; one or fewer is sequential
+	snapshotListingWorkers int
+
+	// how many snapshots each volume may have; zero is unlimited
+	maxSnapshotsPerVolume int
+
+	// builds subvolume creation tokens from volume names when set; otherwise the default scheme is used
+	nameTemplate *template.Template
+
+	// how far ahead of the local clock a backend-reported snapshot creation time may be before it is suspect
+	clockSkewThreshold time.Duration
+
+	// how old an orphaned temporary restore subvolume must be before Initialize deletes it; zero disables cleanup
+	tempSubvolumeCleanupAge time.Duration
+
+	// in-flight subvolume operations, so a retried operation can resume waiting on the original poller
+	pollers *pollerCache
+
+	// limits how quickly subvolumes are created and deleted on each parent volume; nil is unlimited
+	volumeRateLimiters *volumeRateLimiters
+
+	// key is subvolume ID and value can be snapshot ID or empty
+	subvolumesToDelete     map[string]string
+	subvolumesToDeleteLock *sync.Mutex
+
+	// rotates the starting point when choosing among equally full file pool volumes
+	nextFilePoolVolume     int
+	nextFilePoolVolumeLock *sync.Mutex
+
+	// key is parent volume full name; a zero cache age disables caching
+	parentVolumeCache     map[string]*parentVolumeCacheEntry
+	parentVolumeCacheLock *sync.Mutex
+	parentVolumeCacheAge  time.Duration
 
 	physicalPools map[string]storage.Pool
 	virtualPools  map[string]storage.Pool
@@ -160,13 +389,56 @@ func (d *NASBlockStorageDriver) Name() string {
 	return tridentconfig.AzureNASBlockStorageDriverName
 }
 
-// defaultBackendName returns the default name of the backend managed by this driver instance.
+// defaultBackendName returns the default name of the backend managed by this driver instance.  The name is
+// truncated if needed so it never exceeds maxDefaultBackendNameLength.  A client ID that is not a GUID is still used
+// so that existing backend names do not change; validate warns about it instead.
 func (d *NASBlockStorageDriver) defaultBackendName() string {
-	id := utils.RandomString(6)
+	var id string
 	if len(d.Config.ClientID) > 5 {
 		id = d.Config.ClientID[0:5]
+	} else {
+		id = utils.RandomString(d.backendNameSuffixLength())
+	}
+
+	name := fmt.Sprintf("%s_%s", strings.Replace(d.Name(), "-", "", -1), id)
+	if len(name) > maxDefaultBackendNameLength {
+		name = name[:maxDefaultBackendNameLength]
+	}
+	return name
+}
+
+// backendNameSuffixLength returns the length of the random suffix used in the default backend name.  An unset or
+// invalid value yields the default length; validate reports invalid values.
+func (d *NASBlockStorageDriver) backendNameSuffixLength() int {
+	length, err := parseBackendNameSuffixLength(d.Config.BackendNameSuffixLength)
+	if err != nil {
+		return defaultBackendNameSuffixLength
+	}
+	return length
+}
+
+// isWellFormedClientID returns whether a client ID is a GUID, as Azure assigns to every application and managed
+// identity.
+func isWellFormedClientID(clientID string) bool {
+	_, err := uuid.Parse(clientID)
+	return err == nil
+}
+
+// parseBackendNameSuffixLength parses a configured default backend name suffix length, which must be a positive
+// integer no greater than maxDefaultBackendNameLength.
+func parseBackendNameSuffixLength(value string) (int, error) {
+	if value == "" {
+		return defaultBackendNameSuffixLength, nil
+	}
+	length, err := strconv.ParseUint(value, 10, 31)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for backendNameSuffixLength: %v", err)
+	}
+	if length == 0 || length > maxDefaultBackendNameLength {
+		return 0, fmt.Errorf("invalid value for backendNameSuffixLength: %s must be between 1 and %d", value,
+			maxDefaultBackendNameLength)
 	}
-	return fmt.Sprintf("%s_%s", strings.Replace(d.Name(), "-", "", -1), id)
+	return int(length), nil
 }
 
 // BackendName returns the name of the backend managed by this driver instance.
@@ -179,11 +451,34 @@ func (d *NASBlockStorageDriver) BackendName() string {
 	}
 }
 
+// userAgent identifies the Azure requests made by this backend, for support cases and throttling diagnostics.  Any
+// configured suffix is added to the Trident version and backend name.
+func (d *NASBlockStorageDriver) userAgent() string {
+	userAgent := fmt.Sprintf("%s/%s backend/%s", tridentconfig.OrchestratorName,
+		tridentconfig.OrchestratorTelemetry.TridentVersion, d.BackendName())
+	if d.Config.UserAgentSuffix != "" {
+		userAgent += " " + d.Config.UserAgentSuffix
+	}
+	return userAgent
+}
+
 // poolName constructs the name of the pool reported by this driver instance.
 func (d *NASBlockStorageDriver) poolName(name string) string {
 	return fmt.Sprintf("%s_%s", d.BackendName(), strings.Replace(name, "-", "", -1))
 }
 
+// validateSubvolumeNameCase checks that a subvolume found by its creation token really has that name.  Azure
+// resource IDs are case-insensitive, so the lookup also finds a subvolume whose name differs only in case, which is
+// a different subvolume that must not be mistaken for the one being created.
+func validateSubvolumeNameCase(creationToken, extantName string) error {
+	if extantName == "" || extantName == creationToken || !strings.EqualFold(extantName, creationToken) {
+		return nil
+	}
+
+	return fmt.Errorf("subvolume '%s' cannot be created because subvolume '%s' already exists, and Azure treats "+
+		"subvolume names that differ only in case as the same name", creationToken, extantName)
+}
+
 // validateVolumeName checks that the name of a new volume matches the requirements of an ANF subvolume name.
 func (d *NASBlockStorageDriver) validateVolumeName(name string) error {
 	if !subvolumeNameRegex.MatchString(name) {
@@ -219,6 +514,87 @@ func (d *NASBlockStorageDriver) validateCreationToken(name string) error {
 	return nil
 }
 
+// validateSnapshotInternalNameLength checks that a snapshot's internal name fits within a creation token.  If it
+// does not, the error gives the length of each part of the name, so users know how much to shorten the snapshot name.
+func (d *NASBlockStorageDriver) validateSnapshotInternalNameLength(snapshotInternalName, volName string) error {
+	if len(snapshotInternalName) <= maxCreationTokenLength {
+		return nil
+	}
+
+	prefixLength := len(storagePrefixWithSeparator(*d.helper.Config.StoragePrefix))
+	suffixLength := len(snapshotNameSeparator) + len(d.helper.GetSnapshotSuffix(volName))
+	snapNameLength := len(snapshotInternalName) - prefixLength - suffixLength
+	excess := len(snapshotInternalName) - maxCreationTokenLength
+
+	return fmt.Errorf("snapshot internal name '%s' is %d characters long, which exceeds the limit of %d; it is "+
+		"made up of the storage prefix (%d characters), the snapshot name (%d characters), and the volume suffix "+
+		"(%d characters), so the snapshot name must be shortened by at least %d characters", snapshotInternalName,
+		len(snapshotInternalName), maxCreationTokenLength, prefixLength, snapNameLength, suffixLength, excess)
+}
+
+// storagePrefixWithSeparator returns the storage prefix together with the hyphen that separates it from the rest of
+// an internal name.  An explicitly empty prefix means internal names are not prefixed at all, so it has no separator.
+func storagePrefixWithSeparator(storagePrefix string) string {
+	if storagePrefix == "" {
+		return ""
+	}
+	return storagePrefix + "-"
+}
+
+// validateStoragePrefixInternalNameLength checks that the internal names of the longest allowed volume and
+// snapshot names, once combined with the storage prefix and suffixes, still fit within a creation token.
+func validateStoragePrefixInternalNameLength(storagePrefix string) error {
+	// prefix-<volume name>-file-0
+	volumeNameLength := len(storagePrefixWithSeparator(storagePrefix)) + maxSubvolumeNameLength +
+		len(api.SubvolumeNameSeparator+"0")
+
+	// prefix-<snapshot name>--<suffix>
+	snapshotNameLength := len(storagePrefixWithSeparator(storagePrefix)) + maxSubvolumeSnapshotNameLength +
+		len(snapshotNameSeparator) + snapshotSuffixLength
+
+	if volumeNameLength > maxCreationTokenLength || snapshotNameLength > maxCreationTokenLength {
+		return fmt.Errorf("storage prefix %s is too long; internal subvolume names could exceed %d characters",
+			storagePrefix, maxCreationTokenLength)
+	}
+
+	return nil
+}
+
+// validateStoragePrefixNamingRules checks that internal names built from the storage prefix are allowed by the
+// cloud service and can be parsed back into their volume and snapshot components.
+func validateStoragePrefixNamingRules(storagePrefix string) error {
+	// Ensure storage prefix is compatible with cloud service
+	if err := validateStoragePrefix(storagePrefix); err != nil {
+		return err
+	}
+
+	// Ensure the longest possible internal names built from the storage prefix are valid creation tokens
+	if err := validateStoragePrefixInternalNameLength(storagePrefix); err != nil {
+		return err
+	}
+
+	// Ensure storage prefix does not allow -- or ends with '-'
+	if strings.Contains(storagePrefix, snapshotNameSeparator) {
+		return fmt.Errorf("storage prefix '%s' contains '%s'", storagePrefix, snapshotNameSeparator)
+	} else if strings.HasSuffix(storagePrefix, "-") {
+		return fmt.Errorf("storage prefix '%s' ends with '-'", storagePrefix)
+	}
+
+	return nil
+}
+
+// defaultSubvolumeStoragePrefix derives the storage prefix used when none is configured from the driver context's
+// default prefix.  Underscores are not allowed in subvolume names, so they are stripped, and because that may leave
+// a prefix that breaks the naming rules the result is validated like a user-supplied prefix.
+func defaultSubvolumeStoragePrefix(defaultPrefix string) (string, error) {
+	storagePrefix := strings.Replace(defaultPrefix, "_", "", -1)
+	if err := validateStoragePrefixNamingRules(storagePrefix); err != nil {
+		return "", fmt.Errorf("default storage prefix '%s' is not valid for ANF subvolumes, so storagePrefix "+
+			"must be set in the backend config; %v", storagePrefix, err)
+	}
+	return storagePrefix, nil
+}
+
 // defaultCreateTimeout sets the driver timeout for volume create/delete operations.  Docker gets more time, since
 // it doesn't have a mechanism to retry.
 func (d *NASBlockStorageDriver) defaultCreateTimeout() time.Duration {
@@ -240,6 +616,23 @@ func (d *NASBlockStorageDriver) defaultTimeout() time.Duration {
 	}
 }
 
+// parseOperationTimeout parses an optional per-operation timeout, in seconds, falling back to defaultTimeout.
+func (d *NASBlockStorageDriver) parseOperationTimeout(
+	ctx context.Context, operation, timeout string,
+) (time.Duration, error) {
+	if timeout == "" {
+		return d.defaultTimeout(), nil
+	}
+
+	i, err := strconv.ParseUint(timeout, 10, 64)
+	if err != nil {
+		Logc(ctx).WithField("interval", timeout).WithError(err).Errorf("Invalid %s timeout period.", operation)
+		return 0, err
+	}
+
+	return time.Duration(i) * time.Second, nil
+}
+
 // Initialize initializes this driver from the provided config.
 func (d *NASBlockStorageDriver) Initialize(
 	ctx context.Context, context tridentconfig.DriverContext, configJSON string,
@@ -263,7 +656,9 @@ func (d *NASBlockStorageDriver) Initialize(
 	}
 	d.Config = *config
 
-	d.populateConfigurationDefaults(ctx, &d.Config)
+	if err = d.populateConfigurationDefaults(ctx, &d.Config); err != nil {
+		return fmt.Errorf("error initializing %s driver; %v", d.Name(), err)
+	}
 
 	// Should be called after ensuring a value for storage config is set
 	d.helper = NewFileHelper(d.Config, context)
@@ -300,6 +695,110 @@ func (d *NASBlockStorageDriver) Initialize(
 	}
 	d.volumeCreateTimeout = volumeCreateTimeout
 
+	if d.deleteTimeout, err = d.parseOperationTimeout(ctx, "delete", d.Config.DeleteTimeout); err != nil {
+		return err
+	}
+	if d.resizeTimeout, err = d.parseOperationTimeout(ctx, "resize", d.Config.ResizeTimeout); err != nil {
+		return err
+	}
+	if d.snapshotTimeout, err = d.parseOperationTimeout(ctx, "snapshot", d.Config.SnapshotTimeout); err != nil {
+		return err
+	}
+
+	parentVolumeLookupTimeout := defaultParentVolumeLookupTimeout
+	if config.ParentVolumeLookupTimeout != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.ParentVolumeLookupTimeout, 10, 64); parseErr != nil {
+			Logc(ctx).WithField("interval", d.Config.ParentVolumeLookupTimeout).WithError(parseErr).Error(
+				"Invalid parent volume lookup timeout period.")
+			return parseErr
+		} else {
+			parentVolumeLookupTimeout = time.Duration(i) * time.Second
+		}
+	}
+	d.parentVolumeLookupTimeout = parentVolumeLookupTimeout
+
+	deleteRetryCount := defaultDeleteRetryCount
+	if config.DeleteRetryCount != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.DeleteRetryCount, 10, 31); parseErr != nil {
+			Logc(ctx).WithField("count", d.Config.DeleteRetryCount).WithError(parseErr).Error(
+				"Invalid delete retry count.")
+			return parseErr
+		} else {
+			deleteRetryCount = int(i)
+		}
+	}
+	d.deleteRetryCount = deleteRetryCount
+
+	snapshotListingWorkers := defaultSnapshotListingWorkers
+	if config.SnapshotListingWorkers != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.SnapshotListingWorkers, 10, 31); parseErr != nil {
+			Logc(ctx).WithField("workers", d.Config.SnapshotListingWorkers).WithError(parseErr).Error(
+				"Invalid snapshot listing worker count.")
+			return parseErr
+		} else {
+			snapshotListingWorkers = int(i)
+		}
+	}
+	d.snapshotListingWorkers = snapshotListingWorkers
+
+	maxSnapshotsPerVolume := 0
+	if config.MaxSnapshotsPerVolume != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.MaxSnapshotsPerVolume, 10, 31); parseErr != nil {
+			Logc(ctx).WithField("limit", d.Config.MaxSnapshotsPerVolume).WithError(parseErr).Error(
+				"Invalid value for max snapshots per volume.")
+			return parseErr
+		} else {
+			maxSnapshotsPerVolume = int(i)
+		}
+	}
+	d.maxSnapshotsPerVolume = maxSnapshotsPerVolume
+
+	maxOpsPerSecondPerVolume := 0
+	if config.MaxOpsPerSecondPerVolume != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.MaxOpsPerSecondPerVolume, 10, 31); parseErr != nil {
+			Logc(ctx).WithField("limit", d.Config.MaxOpsPerSecondPerVolume).WithError(parseErr).Error(
+				"Invalid value for max operations per second per volume.")
+			return parseErr
+		} else {
+			maxOpsPerSecondPerVolume = int(i)
+		}
+	}
+	d.volumeRateLimiters = newVolumeRateLimiters(maxOpsPerSecondPerVolume)
+
+	if d.nameTemplate, err = parseNameTemplate(d.Config.NameTemplate, *d.Config.StoragePrefix); err != nil {
+		Logc(ctx).WithField("nameTemplate", d.Config.NameTemplate).WithError(err).Error("Invalid name template.")
+		return fmt.Errorf("invalid value for nameTemplate; %v", err)
+	}
+
+	clockSkewThreshold := defaultClockSkewThreshold
+	if config.ClockSkewThreshold != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.ClockSkewThreshold, 10, 64); parseErr != nil {
+			Logc(ctx).WithField("interval", d.Config.ClockSkewThreshold).WithError(parseErr).Error(
+				"Invalid clock skew threshold.")
+			return parseErr
+		} else {
+			clockSkewThreshold = time.Duration(i) * time.Second
+		}
+	}
+	d.clockSkewThreshold = clockSkewThreshold
+
+	if config.TempSubvolumeCleanupAge != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.TempSubvolumeCleanupAge, 10, 64); parseErr != nil {
+			Logc(ctx).WithField("interval", d.Config.TempSubvolumeCleanupAge).WithError(parseErr).Error(
+				"Invalid temporary subvolume cleanup age.")
+			return parseErr
+		} else {
+			d.tempSubvolumeCleanupAge = time.Duration(i) * time.Second
+		}
+	}
+
+	d.pollers = newPollerCache()
+	d.subvolumesToDelete = make(map[string]string)
+	d.subvolumesToDeleteLock = &sync.Mutex{}
+	d.nextFilePoolVolumeLock = &sync.Mutex{}
+	d.parentVolumeCache = make(map[string]*parentVolumeCacheEntry)
+	d.parentVolumeCacheLock = &sync.Mutex{}
+
 	telemetry := tridentconfig.OrchestratorTelemetry
 	telemetry.TridentBackendUUID = backendUUID
 	d.telemetry = &Telemetry{
@@ -307,6 +806,16 @@ func (d *NASBlockStorageDriver) Initialize(
 		Plugin:    d.Name(),
 	}
 
+	if err = d.applyExportRules(ctx); err != nil {
+		return fmt.Errorf("could not apply export rules; %v", err)
+	}
+
+	if d.Config.DryRun {
+		Logc(ctx).Warning("Dry-run mode is enabled; no subvolumes, snapshots or export policies will be changed.")
+	} else {
+		d.cleanupOrphanedTempSubvolumes(ctx)
+	}
+
 	Logc(ctx).WithFields(LogFields{
 		"StoragePrefix":              *config.StoragePrefix,
 		"Size":                       config.Size,
@@ -323,28 +832,43 @@ func (d *NASBlockStorageDriver) Initialized() bool {
 	return d.initialized
 }
 
-// Terminate stops the driver prior to its being unloaded.
+// Terminate stops the driver prior to its being unloaded.  Saved pollers and pending snapshot-context deletes
+// belong to this driver instance, so they are discarded rather than carried into a reloaded backend, which
+// rediscovers in-flight operations from Azure.
 func (d *NASBlockStorageDriver) Terminate(ctx context.Context, _ string) {
 	fields := LogFields{"Method": "Terminate", "Type": "NASBlockStorageDriver"}
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Terminate")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Terminate")
 
+	if d.pollers != nil {
+		d.pollers.Clear()
+	}
+
+	if d.subvolumesToDeleteLock != nil {
+		d.subvolumesToDeleteLock.Lock()
+		d.subvolumesToDelete = make(map[string]string)
+		d.subvolumesToDeleteLock.Unlock()
+	}
+
 	d.initialized = false
 }
 
 // populateConfigurationDefaults fills in default values for configuration settings if not supplied in the config.
 func (d *NASBlockStorageDriver) populateConfigurationDefaults(
 	ctx context.Context, config *drivers.AzureNASStorageDriverConfig,
-) {
+) error {
 	fields := LogFields{"Method": "populateConfigurationDefaults", "Type": "NASBlockStorageDriver"}
 	Logd(ctx, config.StorageDriverName,
 		config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> populateConfigurationDefaults")
 	defer Logd(ctx, config.StorageDriverName,
 		config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< populateConfigurationDefaults")
 
+	// Only an unset prefix is defaulted; an explicitly empty prefix means internal names are not prefixed
 	if config.StoragePrefix == nil {
-		defaultPrefix := drivers.GetDefaultStoragePrefix(config.DriverContext)
-		defaultPrefix = strings.Replace(defaultPrefix, "_", "", -1)
+		defaultPrefix, err := defaultSubvolumeStoragePrefix(drivers.GetDefaultStoragePrefix(config.DriverContext))
+		if err != nil {
+			return err
+		}
 		config.StoragePrefix = &defaultPrefix
 	}
 
@@ -355,15 +879,28 @@ func (d *NASBlockStorageDriver) populateConfigurationDefaults(
 	if config.LimitVolumeSize == "" {
 		config.LimitVolumeSize = defaultLimitVolumeSize
 	}
+
+	if config.AutoExportCIDRs == nil {
+		config.AutoExportCIDRs = []string{defaultAutoExportCIDR}
+	}
+
+	// Without any mount options, only the NFS version is set at publish, leaving the mount to the client's
+	// defaults, which may be unsafe (e.g. soft mounts).  Existing backends rely on that, so it is only flagged.
+	if config.NfsMountOptions == "" {
+		Logc(ctx).Warning("No nfsMountOptions specified; the client's default NFS mount options will be used.")
+	}
+
 	Logc(ctx).WithFields(LogFields{
-		"StoragePrefix":   *config.StoragePrefix,
-		"Size":            config.Size,
-		"ServiceLevel":    config.ServiceLevel,
-		"NfsMountOptions": config.NfsMountOptions,
-		"LimitVolumeSize": config.LimitVolumeSize,
+		"StoragePrefix":    *config.StoragePrefix,
+		"Size":             config.Size,
+		"ServiceLevel":     config.ServiceLevel,
+		"NfsMountOptions":  config.NfsMountOptions,
+		"LimitVolumeSize":  config.LimitVolumeSize,
+		"AutoExportPolicy": config.AutoExportPolicy,
+		"AutoExportCIDRs":  config.AutoExportCIDRs,
 	}).Debugf("Configuration defaults")
 
-	return
+	return nil
 }
 
 // initializeStoragePools defines the pools reported to Trident, whether physical or virtual.
@@ -388,8 +925,14 @@ func (d *NASBlockStorageDriver) initializeStoragePools(
 		protocolTypes = api.ProtocolTypeNFSv41
 	}
 
+	// Whether any file pool volume, in any pool, supports the NFS version requested in the mount options
+	protocolSupported := false
+
 	if len(d.Config.FilePoolVolumes) > 0 {
 		filePoolVolumes, err := d.SDK.ValidateFilePoolVolumes(ctx, d.Config.FilePoolVolumes)
+		if err == nil {
+			err = d.validateFilePoolVolumeServiceLevels(ctx, d.Config.ServiceLevel, filePoolVolumes)
+		}
 		if err != nil {
 			return nil, nil, fmt.Errorf("error initializing physical pools: %v", err)
 		}
@@ -398,7 +941,10 @@ func (d *NASBlockStorageDriver) initializeStoragePools(
 			name := fmt.Sprintf("%s_%s", filePoolVolume.Name, d.createFilePoolVolumePathHash(filePoolVolume))
 			poolName := strings.Replace(name, "-", "", -1)
 
-			if protocolTypes != "" && filePoolVolume.ProtocolTypes[0] != protocolTypes {
+			protocolSupported = protocolSupported || filePoolVolumeSupportsProtocol(filePoolVolume, protocolTypes)
+
+			if protocolTypes != "" && len(filePoolVolume.ProtocolTypes) > 0 &&
+				filePoolVolume.ProtocolTypes[0] != protocolTypes {
 				Logc(ctx).Warnf("Protocol for filePoolVolume '%s' in pool '%s' is '%s' which does not match"+
 					" NFSMountOptions's NFS version '%s'; thus NFSMountOptions version will be ignored",
 					filePoolVolume.FullName, poolName, filePoolVolume.ProtocolTypes[0], protocolTypes)
@@ -421,6 +967,7 @@ func (d *NASBlockStorageDriver) initializeStoragePools(
 			}
 
 			pool.InternalAttributes()[Size] = d.Config.Size
+			pool.InternalAttributes()[UnixPermissions] = d.Config.UnixPermissions
 			pool.InternalAttributes()[FilePoolVolumes] = filePoolVolume.FullName
 
 			pool.SetSupportedTopologies(d.Config.SupportedTopologies)
@@ -452,23 +999,39 @@ func (d *NASBlockStorageDriver) initializeStoragePools(
 				size = vpool.Size
 			}
 
+			unixPermissions := d.Config.UnixPermissions
+			if vpool.UnixPermissions != "" {
+				unixPermissions = vpool.UnixPermissions
+			}
+
 			supportedTopologies := d.Config.SupportedTopologies
 			if vpool.SupportedTopologies != nil {
 				supportedTopologies = vpool.SupportedTopologies
 			}
 
+			serviceLevel := d.Config.ServiceLevel
+			if vpool.ServiceLevel != "" {
+				serviceLevel = vpool.ServiceLevel
+			}
+
 			configFilePoolVolumes := d.Config.FilePoolVolumes
 			if vpool.FilePoolVolumes != nil {
 				configFilePoolVolumes = vpool.FilePoolVolumes
 			}
 
 			filePoolVolumes, err := d.SDK.ValidateFilePoolVolumes(ctx, configFilePoolVolumes)
+			if err == nil {
+				err = d.validateFilePoolVolumeServiceLevels(ctx, serviceLevel, filePoolVolumes)
+			}
 			if err != nil {
 				return nil, nil, fmt.Errorf("error initializing virtual pool '%s': %v", poolName, err)
 			}
 
 			for _, filePoolVolume := range filePoolVolumes {
-				if protocolTypes != "" && filePoolVolume.ProtocolTypes[0] != protocolTypes {
+				protocolSupported = protocolSupported || filePoolVolumeSupportsProtocol(filePoolVolume, protocolTypes)
+
+				if protocolTypes != "" && len(filePoolVolume.ProtocolTypes) > 0 &&
+					filePoolVolume.ProtocolTypes[0] != protocolTypes {
 					Logc(ctx).Warnf("Protocol for filePoolVolume '%s' in pool '%s' is '%s' which does not match"+
 						" NFSMountOptions's NFS version '%s'; thus NFSMountOptions version will be ignored",
 						filePoolVolume.FullName, poolName, filePoolVolume.ProtocolTypes[0], protocolTypes)
@@ -490,9 +1053,14 @@ func (d *NASBlockStorageDriver) initializeStoragePools(
 				pool.Attributes()[sa.Zone] = sa.NewStringOffer(zone)
 			}
 
+			filePoolVolumeNames := make([]string, 0, len(filePoolVolumes))
+			for _, filePoolVolume := range filePoolVolumes {
+				filePoolVolumeNames = append(filePoolVolumeNames, filePoolVolume.FullName)
+			}
+
 			pool.InternalAttributes()[Size] = size
-			// TODO: When supporting multiple filePoolVolumes this will change
-			pool.InternalAttributes()[FilePoolVolumes] = filePoolVolumes[0].FullName
+			pool.InternalAttributes()[UnixPermissions] = unixPermissions
+			pool.InternalAttributes()[FilePoolVolumes] = strings.Join(filePoolVolumeNames, ",")
 
 			pool.SetSupportedTopologies(supportedTopologies)
 
@@ -504,9 +1072,60 @@ func (d *NASBlockStorageDriver) initializeStoragePools(
 		return nil, nil, fmt.Errorf("filePoolVolumes is a required field")
 	}
 
+	// The NFS version in the mount options is ignored for a file pool volume with another protocol, so if no file
+	// pool volume supports it, every mount differs from what was configured
+	if !protocolSupported {
+		if d.Config.StrictProtocolMatch {
+			return nil, nil, fmt.Errorf("no filePoolVolume supports protocol '%s' required by nfsMountOptions '%s'",
+				protocolTypes, d.Config.NfsMountOptions)
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"protocol":        protocolTypes,
+			"nfsMountOptions": d.Config.NfsMountOptions,
+		}).Warning("No file pool volume supports the NFS version in nfsMountOptions.")
+	}
+
 	return physicalPools, virtualPools, nil
 }
 
+// filePoolVolumeSupportsProtocol reports whether a file pool volume supports an NFS protocol type.  Any protocol is
+// supported if none is requested, or if the file pool volume reports no protocol types.
+func filePoolVolumeSupportsProtocol(filePoolVolume *api.FileSystem, protocolType string) bool {
+	return protocolType == "" || len(filePoolVolume.ProtocolTypes) == 0 ||
+		utils.SliceContainsString(filePoolVolume.ProtocolTypes, protocolType)
+}
+
+// validateFilePoolVolumeServiceLevels compares the service level of each file pool volume's capacity pool with the
+// configured service level.  Subvolumes take their performance from the parent volume, so a mismatch is logged, or
+// rejected if the backend is configured to reject service level mismatches.
+func (d *NASBlockStorageDriver) validateFilePoolVolumeServiceLevels(
+	ctx context.Context, serviceLevel string, filePoolVolumes []*api.FileSystem,
+) error {
+	if serviceLevel == "" {
+		return nil
+	}
+
+	for _, filePoolVolume := range filePoolVolumes {
+		if filePoolVolume.ServiceLevel == "" || strings.EqualFold(filePoolVolume.ServiceLevel, serviceLevel) {
+			continue
+		}
+
+		if d.Config.RejectServiceLevelMismatch {
+			return fmt.Errorf("filePoolVolume '%s' has service level '%s', not the configured service level '%s'",
+				filePoolVolume.FullName, filePoolVolume.ServiceLevel, serviceLevel)
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"filePoolVolume":         filePoolVolume.FullName,
+			"serviceLevel":           filePoolVolume.ServiceLevel,
+			"configuredServiceLevel": serviceLevel,
+		}).Warning("File pool volume's service level does not match the configured service level.")
+	}
+
+	return nil
+}
+
 // initializeAzureConfig parses the Azure config, mixing in the specified common config.
 func (d *NASBlockStorageDriver) initializeAzureConfig(
 	ctx context.Context, configJSON string, commonConfig *drivers.CommonStorageDriverConfig,
@@ -568,28 +1187,90 @@ func (d *NASBlockStorageDriver) initializeAzureSDKClient(
 		}
 	}
 
-	clientConfig := api.ClientConfig{
-		SubscriptionID: config.SubscriptionID,
-		AzureAuthConfig: azclient.AzureAuthConfig{
-			AADClientID:     config.ClientID,
-			AADClientSecret: config.ClientSecret,
-		},
-		TenantID:          config.TenantID,
-		Location:          config.Location,
-		StorageDriverName: config.StorageDriverName,
-		DebugTraceFlags:   config.DebugTraceFlags,
-		SDKTimeout:        sdkTimeout,
-		MaxCacheAge:       maxCacheAge,
+	// Parent volume lookups are cached only briefly, and never longer than the discovery cache
+	d.parentVolumeCacheAge = maxParentVolumeCacheAge
+	if maxCacheAge < d.parentVolumeCacheAge {
+		d.parentVolumeCacheAge = maxCacheAge
 	}
 
-	// Try ANF Subvolume driver initialization with Azure workload identity followed by Azure managed identity,
-	// when both are not present , default to credentials present in the backend configuration file.
+	maxSubvolumesListed := 0
+	if config.MaxSubvolumesListed != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.MaxSubvolumesListed, 10, 31); parseErr != nil {
+			Logc(ctx).WithField("limit", d.Config.MaxSubvolumesListed).WithError(parseErr).Error(
+				"Invalid value for max subvolumes listed.")
+			return parseErr
+		} else {
+			maxSubvolumesListed = int(i)
+		}
+	}
+	d.maxSubvolumesListed = maxSubvolumesListed
 
-	// Azure workload identity
-	// If cloud identity is provided and cloud provider is set to 'Azure' during the installation,
-	// we can use AZURE_CLIENT_ID,AZURE_TENANT_ID,AZURE_FEDERATED_TOKEN_FILE and AZURE_AUTHORITY_HOST environment variables
-	// injected by workload identity webhook for initialization of ANF Subvolume driver.
-	if os.Getenv("AZURE_CLIENT_ID") != "" && os.Getenv("AZURE_TENANT_ID") != "" && os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != "" && os.Getenv("AZURE_AUTHORITY_HOST") != "" {
+	pollInterval := api.DefaultPollInterval
+	if config.PollInterval != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.PollInterval, 10, 64); parseErr != nil {
+			Logc(ctx).WithField("interval", d.Config.PollInterval).WithError(parseErr).Error(
+				"Invalid value for poll interval.")
+			return parseErr
+		} else {
+			pollInterval = time.Duration(i) * time.Second
+		}
+	}
+
+	maxPollInterval := api.DefaultMaxPollInterval
+	if config.MaxPollInterval != "" {
+		if i, parseErr := strconv.ParseUint(d.Config.MaxPollInterval, 10, 64); parseErr != nil {
+			Logc(ctx).WithField("interval", d.Config.MaxPollInterval).WithError(parseErr).Error(
+				"Invalid value for max poll interval.")
+			return parseErr
+		} else {
+			maxPollInterval = time.Duration(i) * time.Second
+		}
+	} else if maxPollInterval < pollInterval {
+		maxPollInterval = pollInterval
+	}
+
+	if pollInterval == 0 || maxPollInterval < pollInterval {
+		return fmt.Errorf("invalid poll intervals; pollInterval (%v) must be nonzero and no greater than "+
+			"maxPollInterval (%v)", pollInterval, maxPollInterval)
+	}
+
+	// The suffix is sent in a header of every request, so it may not contain control characters
+	if strings.IndexFunc(config.UserAgentSuffix, unicode.IsControl) >= 0 {
+		return fmt.Errorf("userAgentSuffix %q may not contain control characters", config.UserAgentSuffix)
+	}
+
+	clientConfig := api.ClientConfig{
+		SubscriptionID: config.SubscriptionID,
+		AzureAuthConfig: azclient.AzureAuthConfig{
+			AADClientID:     config.ClientID,
+			AADClientSecret: config.ClientSecret,
+		},
+		TenantID:            config.TenantID,
+		Location:            config.Location,
+		CloudEnvironment:    config.CloudEnvironment,
+		StorageDriverName:   config.StorageDriverName,
+		DebugTraceFlags:     config.DebugTraceFlags,
+		SDKTimeout:          sdkTimeout,
+		MaxCacheAge:         maxCacheAge,
+		MaxSubvolumesListed: maxSubvolumesListed,
+		PollInterval:        pollInterval,
+		MaxPollInterval:     maxPollInterval,
+		ProxyURL:            config.ProxyURL,
+		UserAgent:           d.userAgent(),
+	}
+
+	if config.ProxyURL != "" {
+		Logc(ctx).Info("Sending Azure requests through the configured proxy.")
+	}
+
+	// Try ANF Subvolume driver initialization with Azure workload identity followed by Azure managed identity,
+	// when both are not present , default to credentials present in the backend configuration file.
+
+	// Azure workload identity
+	// If cloud identity is provided and cloud provider is set to 'Azure' during the installation,
+	// we can use AZURE_CLIENT_ID,AZURE_TENANT_ID,AZURE_FEDERATED_TOKEN_FILE and AZURE_AUTHORITY_HOST environment variables
+	// injected by workload identity webhook for initialization of ANF Subvolume driver.
+	if os.Getenv("AZURE_CLIENT_ID") != "" && os.Getenv("AZURE_TENANT_ID") != "" && os.Getenv("AZURE_FEDERATED_TOKEN_FILE") != "" && os.Getenv("AZURE_AUTHORITY_HOST") != "" {
 		Logc(ctx).Info("Using Azure workload identity.")
 	} else {
 		// Azure managed identity
@@ -610,6 +1291,20 @@ func (d *NASBlockStorageDriver) initializeAzureSDKClient(
 			// Set SubscriptionID
 			d.Config.SubscriptionID = clientConfig.SubscriptionID
 		}
+
+		// With managed identity, the subscription need not be configured anywhere, so ask the instance metadata
+		// service, as subvolume IDs cannot be built without it
+		if clientConfig.SubscriptionID == "" && config.ClientID == "" && config.ClientSecret == "" {
+			subscriptionID, err := getSubscriptionIDFromIMDS(ctx)
+			if err != nil {
+				return fmt.Errorf("subscriptionID is not set in the backend config or Azure credential file, "+
+					"and could not be read from the Azure instance metadata service; %v", err)
+			}
+			Logc(ctx).WithField("subscriptionID", subscriptionID).Info("Using subscription from instance metadata.")
+
+			clientConfig.SubscriptionID = subscriptionID
+			d.Config.SubscriptionID = subscriptionID
+		}
 	}
 
 	client, err := api.NewDriver(clientConfig)
@@ -626,6 +1321,41 @@ func (d *NASBlockStorageDriver) initializeAzureSDKClient(
 	return d.SDK.Init(ctx, nil)
 }
 
+// getSubscriptionIDFromIMDS reads the subscription of the VM Trident runs on from the Azure instance metadata service.
+func getSubscriptionIDFromIMDS(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, imdsTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, imdsSubscriptionURL, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Metadata", "true")
+
+	// The metadata service is link-local, so it must never be reached through a proxy
+	client := &http.Client{Transport: &http.Transport{Proxy: nil}}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("instance metadata service returned %s", response.Status)
+	}
+
+	subscriptionID := strings.TrimSpace(string(body))
+	if subscriptionID == "" {
+		return "", errors.New("instance metadata service returned an empty subscription")
+	}
+
+	return subscriptionID, nil
+}
+
 // validate ensures the driver configuration and execution environment are valid and working.
 func (d *NASBlockStorageDriver) validate(ctx context.Context) error {
 	fields := LogFields{"Method": "validate", "Type": "NASBlockStorageDriver"}
@@ -634,27 +1364,134 @@ func (d *NASBlockStorageDriver) validate(ctx context.Context) error {
 
 	storagePrefix := *d.Config.StoragePrefix
 
-	// Ensure storage prefix is compatible with cloud service
-	if err := validateStoragePrefix(storagePrefix); err != nil {
+	// Underscores are stripped from the default prefix, but a user-supplied prefix is used as-is, so call out
+	// underscores specifically rather than failing with the generic character set message below
+	if strings.Contains(storagePrefix, "_") {
+		return fmt.Errorf("storage prefix '%s' contains underscores, which are not allowed in ANF subvolume "+
+			"names; use hyphens instead", storagePrefix)
+	}
+
+	// Ensure length of the storage prefix is within the configured bound
+	maxStoragePrefixLength := defaultMaxStoragePrefixLength
+	if d.Config.MaxStoragePrefixLength != "" {
+		i, err := strconv.ParseUint(d.Config.MaxStoragePrefixLength, 10, 31)
+		if err != nil {
+			return fmt.Errorf("invalid value for maxStoragePrefixLength: %v", err)
+		}
+		maxStoragePrefixLength = int(i)
+	}
+	if len(storagePrefix) > maxStoragePrefixLength {
+		return fmt.Errorf("length of the storage prefix %s should be at most %d", storagePrefix,
+			maxStoragePrefixLength)
+	}
+
+	if err := validateStoragePrefixNamingRules(storagePrefix); err != nil {
 		return err
 	}
 
-	// Ensure length of the storage prefix is <=10
-	if len(storagePrefix) > 10 {
-		return fmt.Errorf("length of the storage prefix %s should be less than 11", *d.Config.StoragePrefix)
+	// The hashed snapshot suffix is longer than the short one, so it leaves less room for the storage prefix
+	if d.Config.HashedSnapshotSuffix {
+		snapshotNameLength := len(storagePrefixWithSeparator(storagePrefix)) + maxSubvolumeSnapshotNameLength +
+			len(snapshotNameSeparator) + hashedSnapshotSuffixLength
+		if snapshotNameLength > maxCreationTokenLength {
+			return fmt.Errorf("storage prefix %s is too long to use hashedSnapshotSuffix; internal snapshot names "+
+				"could exceed %d characters", storagePrefix, maxCreationTokenLength)
+		}
 	}
 
-	// Ensure storage prefix does not allow -- or ends with '-'
-	if strings.Contains(storagePrefix, snapshotNameSeparator) {
-		return fmt.Errorf("storage prefix '%s' contains '%s'", storagePrefix, snapshotNameSeparator)
-	} else if storagePrefix[len(storagePrefix)-1:] == "-" {
-		return fmt.Errorf("storage prefix '%s' ends with '-'", storagePrefix)
+	// Ensure user does not provide "ro" mount option, as it would apply to every subvolume on the backend.
+	// Read-only access is requested per volume instead, with a read-only mount.
+	if utils.AreMountOptionsInList(d.Config.NfsMountOptions, []string{drivers.MountOptionReadOnly}) {
+		return fmt.Errorf("ReadOnly (ro) option is not supported in ANF subvolume backend nfsMountOptions; "+
+			"use a read-only mount instead; %s", d.Config.NfsMountOptions)
+	}
+
+	// Ensure any security flavors are supported, and that Kerberos is only requested with NFSv4.1
+	securityFlavors := getSecurityFlavors(d.Config.NfsMountOptions)
+	for _, flavor := range securityFlavors {
+		if !utils.SliceContainsString(supportedSecurityFlavors, flavor) {
+			return fmt.Errorf("security flavor 'sec=%s' in nfsMountOptions is not supported; must be one of %s",
+				flavor, strings.Join(supportedSecurityFlavors, ", "))
+		}
+	}
+	if hasKerberosSecurityFlavor(securityFlavors) {
+		nfsVersion, err := utils.GetNFSVersionFromMountOptions(d.Config.NfsMountOptions, "", supportedNFSVersions)
+		if err != nil {
+			return err
+		}
+		if nfsVersion == nfsVersion3 {
+			return fmt.Errorf("kerberos security flavors in nfsMountOptions require NFSv4.1; %s",
+				d.Config.NfsMountOptions)
+		}
+	}
+
+	// Ensure any configured export rules list valid clients, and don't compete with the automatic export policy
+	for _, exportRule := range []struct{ option, clients string }{
+		{"exportRule", d.Config.ExportRule},
+		{"exportReadOnlyRule", d.Config.ExportReadOnlyRule},
+	} {
+		if exportRule.clients == "" {
+			continue
+		}
+		if d.Config.AutoExportPolicy {
+			return fmt.Errorf("%s may not be used with autoExportPolicy", exportRule.option)
+		}
+		for _, client := range strings.Split(exportRule.clients, ",") {
+			ipAddr := net.ParseIP(client)
+			_, netAddr, _ := net.ParseCIDR(client)
+			if ipAddr == nil && netAddr == nil {
+				return fmt.Errorf("invalid address/CIDR for %s: %s", exportRule.option, client)
+			}
+		}
+	}
+
+	// Ensure the default backend name suffix length (if any) is usable
+	if _, err := parseBackendNameSuffixLength(d.Config.BackendNameSuffixLength); err != nil {
+		return err
+	}
+
+	// The default backend name is derived from the client ID, so call out one that is not a GUID, since it is
+	// likely a configuration mistake.  An empty client ID is expected when using workload identity.
+	if d.Config.BackendName == "" && d.Config.ClientID != "" && !isWellFormedClientID(d.Config.ClientID) {
+		Logc(ctx).WithField("backendName", d.defaultBackendName()).Warning(
+			"Client ID is not a well-formed GUID; the default backend name is derived from its first characters.")
+	}
+
+	// Ensure the backend unix permissions (if any) are a valid octal mode
+	if d.Config.UnixPermissions != "" {
+		if err := utils.ValidateOctalUnixPermissions(d.Config.UnixPermissions); err != nil {
+			return fmt.Errorf("invalid value for unixPermissions; %v", err)
+		}
+	}
+
+	// Ensure the backend maximum volume size (if any) is parseable, and the minimum (if any) is usable
+	maxVolumeSizeBytes, err := d.getMaxVolumeSizeBytes()
+	if err != nil {
+		return err
+	}
+	minVolumeSizeBytes, err := d.getMinVolumeSizeBytes()
+	if err != nil {
+		return err
+	}
+	if maxVolumeSizeBytes > 0 && minVolumeSizeBytes > maxVolumeSizeBytes {
+		return fmt.Errorf("minimumVolumeSize %s exceeds maxVolumeSize %s", d.Config.MinimumVolumeSize,
+			d.Config.MaxVolumeSize)
 	}
 
-	// Ensure user does not provide "ro" mount option
-	if utils.AreMountOptionsInList(d.Config.NfsMountOptions, []string{"ro"}) {
-		return fmt.Errorf("ReadOnly (ro) option is not supported in ANF subvolume backend nfsMountOptions; %s",
-			d.Config.NfsMountOptions)
+	// Ensure the backend and virtual pool default sizes are parseable, naming the offending field, since
+	// otherwise a bad value is reported only against the pool it was copied into
+	if d.Config.Size != "" {
+		if _, err := utils.ConvertSizeToBytes(d.Config.Size); err != nil {
+			return fmt.Errorf("invalid value for size '%s'; %v", d.Config.Size, err)
+		}
+	}
+	for index, vpool := range d.Config.Storage {
+		if vpool.Size == "" {
+			continue
+		}
+		if _, err := utils.ConvertSizeToBytes(vpool.Size); err != nil {
+			return fmt.Errorf("invalid value for size '%s' in storage[%d]; %v", vpool.Size, index, err)
+		}
 	}
 
 	// Validate pool-level attributes
@@ -673,6 +1510,96 @@ func (d *NASBlockStorageDriver) validate(ctx context.Context) error {
 		if _, err := utils.ConvertSizeToBytes(pool.InternalAttributes()[Size]); err != nil {
 			return fmt.Errorf("invalid value for default volume size in pool %s: %v", pool.Name(), err)
 		}
+
+		// Validate unix permissions
+		if unixPermissions := pool.InternalAttributes()[UnixPermissions]; unixPermissions != "" {
+			if err := utils.ValidateOctalUnixPermissions(unixPermissions); err != nil {
+				return fmt.Errorf("invalid value for unixPermissions in pool %s; %v", pool.Name(), err)
+			}
+		}
+	}
+
+	if err := d.validateVirtualPoolOverlap(ctx); err != nil {
+		return err
+	}
+
+	// Optionally ensure the controller can reach the mount targets of every file pool volume
+	if d.Config.ValidateMountTargetReachability {
+		if err := d.validateMountTargetReachability(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateVirtualPoolOverlap finds virtual pools that share a file pool volume.  Each such pool reports the volume's
+// full capacity, while the backend counts the volume only once, so the overlap is logged, or rejected if the backend
+// is configured to reject overlapping pools.
+func (d *NASBlockStorageDriver) validateVirtualPoolOverlap(ctx context.Context) error {
+	poolsByFilePoolVolume := make(map[string][]string)
+	for _, pool := range d.virtualPools {
+		for _, filePoolVolume := range d.getPoolFilePoolVolumes(pool) {
+			poolsByFilePoolVolume[filePoolVolume] = append(poolsByFilePoolVolume[filePoolVolume], pool.Name())
+		}
+	}
+
+	filePoolVolumes := make([]string, 0, len(poolsByFilePoolVolume))
+	for filePoolVolume := range poolsByFilePoolVolume {
+		filePoolVolumes = append(filePoolVolumes, filePoolVolume)
+	}
+	sort.Strings(filePoolVolumes)
+
+	for _, filePoolVolume := range filePoolVolumes {
+		poolNames := poolsByFilePoolVolume[filePoolVolume]
+		if len(poolNames) < 2 {
+			continue
+		}
+		sort.Strings(poolNames)
+
+		if d.Config.RejectOverlappingPools {
+			return fmt.Errorf("virtual pools %s share file pool volume '%s'", strings.Join(poolNames, ", "),
+				filePoolVolume)
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"filePoolVolume": filePoolVolume,
+			"pools":          poolNames,
+		}).Warning("Virtual pools share a file pool volume; its capacity is counted once for the backend.")
+	}
+
+	return nil
+}
+
+// validateMountTargetReachability attempts a TCP connection to the NFS port of each mount target of each
+// file pool volume.  This catches virtual network and peering misconfigurations early, but the controller's
+// network may differ from that of the nodes, so the check is only performed when enabled in the config.
+func (d *NASBlockStorageDriver) validateMountTargetReachability(ctx context.Context) error {
+	for _, filePoolVolume := range d.getAllFilePoolVolumes() {
+		volume, err := d.getFilePoolVolume(ctx, filePoolVolume)
+		if err != nil {
+			return err
+		}
+
+		if len(volume.MountTargets) == 0 {
+			return fmt.Errorf("volume %s has no mount targets", volume.Name)
+		}
+
+		for _, mountTarget := range volume.MountTargets {
+			address := net.JoinHostPort(mountTarget.IPAddress, nfsPort)
+
+			conn, err := mountTargetDialer("tcp", address, mountTargetProbeTimeout)
+			if err != nil {
+				return fmt.Errorf("mount target %s of file pool volume '%s' is not reachable from the controller; "+
+					"check the virtual network and peering configuration; %v", address, filePoolVolume, err)
+			}
+			_ = conn.Close()
+
+			Logc(ctx).WithFields(LogFields{
+				"volume":      filePoolVolume,
+				"mountTarget": address,
+			}).Debug("Mount target is reachable.")
+		}
 	}
 
 	return nil
@@ -682,7 +1609,7 @@ func (d *NASBlockStorageDriver) validate(ctx context.Context) error {
 func (d *NASBlockStorageDriver) Create(
 	ctx context.Context, volConfig *storage.VolumeConfig,
 	storagePool storage.Pool, volAttributes map[string]sa.Request,
-) error {
+) (err error) {
 	creationToken := volConfig.InternalName
 	fields := LogFields{
 		"Method":        "Create",
@@ -693,6 +1620,9 @@ func (d *NASBlockStorageDriver) Create(
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Create")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Create")
 
+	startTime := time.Now()
+	defer func() { recordSubvolumeOperation(d.BackendName(), "create", startTime, err) }()
+
 	// Make sure we got a valid volume name
 	if err := d.validateVolumeName(volConfig.Name); err != nil {
 		return err
@@ -710,6 +1640,10 @@ func (d *NASBlockStorageDriver) Create(
 	}
 
 	if subvolumeExists {
+		if err = validateSubvolumeNameCase(creationToken, extantSubvolume.Name); err != nil {
+			return err
+		}
+
 		volConfig.InternalName = extantSubvolume.Name
 		volConfig.InternalID = extantSubvolume.ID
 
@@ -724,10 +1658,11 @@ func (d *NASBlockStorageDriver) Create(
 			Operation: Create,
 		}
 
-		poller := pollerResponseCache[pollerKey]
+		poller, _ := d.pollers.Get(pollerKey)
 
 		// Wait for creation to complete
-		if err = d.waitForSubvolumeCreate(ctx, extantSubvolume, poller, pollerKey.Operation, true); err != nil {
+		if err = d.waitForSubvolumeCreate(ctx, extantSubvolume, poller, pollerKey.Operation, true,
+			d.volumeCreateTimeout); err != nil {
 			return err
 		}
 
@@ -754,8 +1689,7 @@ func (d *NASBlockStorageDriver) Create(
 			return fmt.Errorf("invalid size value '%s': %v", defaultSize, err)
 		}
 	}
-	if checkMinVolumeSizeError := drivers.CheckMinVolumeSize(sizeBytes,
-		MinimumSubvolumeSizeBytes); checkMinVolumeSizeError != nil {
+	if checkMinVolumeSizeError := d.checkMinVolumeSize(sizeBytes); checkMinVolumeSizeError != nil {
 		return checkMinVolumeSizeError
 	}
 
@@ -763,27 +1697,59 @@ func (d *NASBlockStorageDriver) Create(
 		return err
 	}
 
+	if err := d.checkMaxVolumeSize(sizeBytes); err != nil {
+		return err
+	}
+
+	// Take the unix permissions from the volume config, falling back to the pool's value
+	unixPermissions := volConfig.UnixPermissions
+	if unixPermissions == "" {
+		unixPermissions = storagePool.InternalAttributes()[UnixPermissions]
+	}
+	if unixPermissions != "" {
+		if err = utils.ValidateOctalUnixPermissions(unixPermissions); err != nil {
+			return fmt.Errorf("could not create volume %s; %v", creationToken, err)
+		}
+	}
+
+	// Choose the parent volume in which to place the subvolume
+	filePoolVolume, err := d.selectFilePoolVolume(ctx, storagePool, sizeBytes)
+	if err != nil {
+		return err
+	}
+
 	// Update config to reflect values used to create volume
 	volConfig.Size = strconv.FormatUint(sizeBytes, 10)
+	volConfig.UnixPermissions = unixPermissions
 
-	Logc(ctx).WithFields(LogFields{
+	Logc(ctx).WithFields(addSizeLogFields(LogFields{
 		"creationToken": creationToken,
-		"size":          sizeBytes,
-		"volume":        storagePool.InternalAttributes()[FilePoolVolumes],
-	}).Debug("Creating subvolume.")
+		"volume":        filePoolVolume,
+	}, "size", sizeBytes)).Debug("Creating subvolume.")
 
 	subvolumeCreateRequest := &api.SubvolumeCreateRequest{
 		CreationToken: creationToken,
-		Volume:        storagePool.InternalAttributes()[FilePoolVolumes],
+		Volume:        filePoolVolume,
 		Size:          int64(sizeBytes),
 		Parent:        "", // Needed only when cloning
 	}
 
+	if d.Config.DryRun {
+		return d.dryRunCreate(ctx, "Create", subvolumeCreateRequest)
+	}
+
+	if err = d.volumeRateLimiters.Wait(ctx, filePoolVolume); err != nil {
+		return err
+	}
+
 	// Create the volume
 	subvolume, poller, err := d.SDK.CreateSubvolume(ctx, subvolumeCreateRequest)
 	if err != nil {
 		return err
 	}
+	if err = d.resolveCreatedSubvolumeID(ctx, subvolume, creationToken, filePoolVolume); err != nil {
+		return err
+	}
 
 	// Always save the ID so we can find the volume efficiently later
 	volConfig.InternalID = subvolume.ID
@@ -794,16 +1760,16 @@ func (d *NASBlockStorageDriver) Create(
 		Operation: Create,
 	}
 
-	pollerResponseCache[pollerKey] = poller
+	d.pollers.Set(pollerKey, poller)
 
 	// Wait for creation to complete
-	return d.waitForSubvolumeCreate(ctx, subvolume, poller, pollerKey.Operation, true)
+	return d.waitForSubvolumeCreate(ctx, subvolume, poller, pollerKey.Operation, true, d.volumeCreateTimeout)
 }
 
 // CreateClone clones an existing volume.  If a snapshot is not specified, one is created.
 func (d *NASBlockStorageDriver) CreateClone(
 	ctx context.Context, sourceVolConfig, volConfig *storage.VolumeConfig, _ storage.Pool,
-) error {
+) (err error) {
 	creationToken := volConfig.InternalName
 	source := volConfig.CloneSourceVolume
 	snapshot := volConfig.CloneSourceSnapshot
@@ -819,6 +1785,9 @@ func (d *NASBlockStorageDriver) CreateClone(
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateClone")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateClone")
 
+	startTime := time.Now()
+	defer func() { recordSubvolumeOperation(d.BackendName(), "create_clone", startTime, err) }()
+
 	// Make sure we got a valid name
 	if err := d.validateVolumeName(volConfig.Name); err != nil {
 		return err
@@ -831,8 +1800,16 @@ func (d *NASBlockStorageDriver) CreateClone(
 
 	sourceInternalID := sourceVolConfig.InternalID
 
+	// Record the clone's lineage, so that it can be reported later even if the caller did not fill it in
+	if volConfig.CloneSourceVolumeInternal == "" {
+		volConfig.CloneSourceVolumeInternal = sourceVolConfig.InternalName
+	}
+
 	// Check if called from CreateClone and is from a snapshot
 	if isFromSnapshot {
+		if volConfig.CloneSourceSnapshotInternal == "" {
+			volConfig.CloneSourceSnapshotInternal = d.helper.GetSnapshotInternalName(sourceVolConfig.Name, snapshot)
+		}
 		snapshotInternalName := volConfig.CloneSourceSnapshotInternal
 
 		subscription, resourceGroup, _, netappAccount, cPoolName, volumeName, _, err := api.ParseSubvolumeID(sourceVolConfig.InternalID)
@@ -857,6 +1834,10 @@ func (d *NASBlockStorageDriver) CreateClone(
 		return fmt.Errorf("error checking for existing subvolume %s; %v", creationToken, err)
 	}
 	if subvolumeExists {
+		if err = validateSubvolumeNameCase(creationToken, extantSubvolume.Name); err != nil {
+			return err
+		}
+
 		volConfig.InternalName = extantSubvolume.Name
 		volConfig.InternalID = extantSubvolume.ID
 
@@ -871,38 +1852,82 @@ func (d *NASBlockStorageDriver) CreateClone(
 			Operation: Create,
 		}
 
-		poller := pollerResponseCache[pollerKey]
+		poller, _ := d.pollers.Get(pollerKey)
 
-		// Wait for creation to complete
-		if err = d.waitForSubvolumeCreate(ctx, extantSubvolume, poller, pollerKey.Operation, true); err != nil {
-			return err
+		// Wait for creation to complete, cleaning up the clone if it failed
+		if err = d.waitForSubvolumeCreate(ctx, extantSubvolume, poller, pollerKey.Operation, false,
+			d.volumeCreateTimeout); err != nil {
+			if errors.IsVolumeCreatingError(err) {
+				return err
+			}
+
+			// A failed wait does not by itself mean the clone is unhealthy, so report it as existing if it is
+			// in fact available, so that retries are recognized as idempotent
+			if stateErr := d.checkSubvolumeCreateState(ctx, extantSubvolume); stateErr != nil {
+				if errors.IsVolumeCreatingError(stateErr) {
+					return stateErr
+				}
+				return err
+			}
 		}
 
 		return drivers.NewVolumeExistsError(volConfig.InternalName)
 	}
 
+	// Determine the clone size, which may be larger (but not smaller) than the source
+	cloneSize, err := d.getCloneSize(ctx, sourceVolConfig, volConfig, sourceSubvolume)
+	if err != nil {
+		return err
+	}
+
+	if err = d.checkMaxVolumeSize(uint64(cloneSize)); err != nil {
+		return err
+	}
+
+	// A clone larger than its source records its own size
+	if cloneSize > sourceSubvolume.Size {
+		volConfig.Size = strconv.FormatInt(cloneSize, 10)
+	}
+
+	// A clone's filesystem starts as a copy of the source's, so keep the source's unix permissions by default
+	if volConfig.UnixPermissions == "" {
+		volConfig.UnixPermissions = sourceVolConfig.UnixPermissions
+	}
+
 	filePoolVolume := api.CreateVolumeFullName(sourceSubvolume.ResourceGroup, sourceSubvolume.NetAppAccount,
 		sourceSubvolume.CapacityPool, sourceSubvolume.Volume)
 
-	Logc(ctx).WithFields(LogFields{
+	// If the size is zero, the clone inherits the size of its parent
+	Logc(ctx).WithFields(addSizeLogFields(LogFields{
 		"creationToken": creationToken,
-		"size":          sourceSubvolume.Size, // This may come out to be zero and has no affect on clone size
 		"volume":        filePoolVolume,
 		"parentPath":    sourceSubvolume.Name,
-	}).Debug("Creating subvolume clone.")
+	}, "size", uint64(cloneSize))).Debug("Creating subvolume clone.")
 
 	// Create the clone based on given file
 	subvolumeCreateRequest := &api.SubvolumeCreateRequest{
 		CreationToken: creationToken,
 		Volume:        filePoolVolume,
-		Size:          sourceSubvolume.Size,
+		Size:          cloneSize,
 		Parent:        sourceSubvolume.Name, // Needed only when cloning
 	}
+
+	if d.Config.DryRun {
+		return d.dryRunCreate(ctx, "CreateClone", subvolumeCreateRequest)
+	}
+
+	if err = d.volumeRateLimiters.Wait(ctx, filePoolVolume); err != nil {
+		return err
+	}
+
 	// Create the volume
 	subvolume, poller, err := d.SDK.CreateSubvolume(ctx, subvolumeCreateRequest)
 	if err != nil {
 		return err
 	}
+	if err = d.resolveCreatedSubvolumeID(ctx, subvolume, creationToken, filePoolVolume); err != nil {
+		return err
+	}
 
 	// Always save the ID so we can find the volume efficiently later
 	volConfig.InternalID = subvolume.ID
@@ -913,84 +1938,393 @@ func (d *NASBlockStorageDriver) CreateClone(
 		Operation: Create,
 	}
 
-	pollerResponseCache[pollerKey] = poller
-
-	// Wait for creation to complete
-	return d.waitForSubvolumeCreate(ctx, subvolume, poller, pollerKey.Operation, true)
-}
-
-// Import finds an existing subvolume and makes it available for containers. If ImportNotManaged is false, the
-// subvolume is fully brought under Trident's management.
-func (d *NASBlockStorageDriver) Import(
-	ctx context.Context, volConfig *storage.VolumeConfig, originalName string,
-) error {
-	fields := LogFields{
-		"Method":       "Import",
-		"Type":         "NASBlockStorageDriver",
-		"originalName": originalName,
-		"newName":      volConfig.InternalName,
-	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Import")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Import")
+	d.pollers.Set(pollerKey, poller)
 
-	// Make sure the original name is in an acceptable format
-	if err := d.validateCreationToken(originalName); err != nil {
+	// Wait for creation to complete.  Unlike Create, a clone has no followup to handle a failure, so any
+	// error is returned here after the failed clone is cleaned up.
+	if err = d.waitForSubvolumeCreate(ctx, subvolume, poller, pollerKey.Operation, false,
+		d.volumeCreateTimeout); err != nil {
 		return err
 	}
 
-	// Ensure subvolume is actually a subvolume, and it isn't a "snapshot" subvolume
-	if !d.isFileValidVolume(ctx, originalName) {
-		return fmt.Errorf("ineligible for import; subvolume %s is a snapshot subvolume", originalName)
+	// If a size larger than the source was requested, make sure the backend honored it
+	if cloneSize != sourceSubvolume.Size {
+		return d.ensureCloneSize(ctx, subvolume, cloneSize)
 	}
 
-	subvolumeWithMetadata, err := d.SDK.SubvolumeByCreationToken(ctx, originalName, d.getAllFilePoolVolumes(), true)
+	return nil
+}
+
+// ensureCloneSize resizes a newly created clone if the backend created it smaller than the requested size.
+func (d *NASBlockStorageDriver) ensureCloneSize(ctx context.Context, clone *api.Subvolume, sizeBytes int64) error {
+	cloneWithMetadata, err := d.SDK.SubvolumeByID(ctx, clone.ID, true)
 	if err != nil {
-		return fmt.Errorf("could not find subvolume %s; %v", originalName, err)
+		return fmt.Errorf("could not read size of clone %s; %v", clone.Name, err)
 	}
 
-	if checkMinVolumeSizeError := drivers.CheckMinVolumeSize(uint64(subvolumeWithMetadata.Size),
-		MinimumSubvolumeSizeBytes); checkMinVolumeSizeError != nil {
-		return fmt.Errorf("size error; %v", checkMinVolumeSizeError)
+	if cloneWithMetadata.Size >= sizeBytes {
+		return nil
 	}
 
-	volConfig.Size = strconv.FormatInt(subvolumeWithMetadata.Size, 10)
+	Logc(ctx).WithFields(LogFields{
+		"clone":         clone.Name,
+		"cloneSize":     cloneWithMetadata.Size,
+		"requestedSize": sizeBytes,
+	}).Debug("Clone is smaller than requested, resizing.")
 
-	// The ANF subvolume creation token cannot be changed, so use it as the internal name
-	volConfig.InternalName = originalName
+	resizeCtx, cancel := context.WithTimeout(ctx, d.resizeTimeout)
+	defer cancel()
 
-	// Always save the ID so we can find the volume efficiently later
-	volConfig.InternalID = subvolumeWithMetadata.ID
+	if err = d.SDK.ResizeSubvolume(resizeCtx, cloneWithMetadata, sizeBytes); err != nil {
+		return fmt.Errorf("could not resize clone %s to requested size %d; %v", clone.Name, sizeBytes, err)
+	}
 
 	return nil
 }
 
-func (d *NASBlockStorageDriver) Rename(ctx context.Context, name, newName string) error {
-	fields := LogFields{
-		"Method":  "Rename",
-		"Type":    "NASBlockStorageDriver",
-		"name":    name,
-		"newName": newName,
+// getMinVolumeSizeBytes returns the backend's minimum volume size in bytes, which is the ANF subvolume minimum
+// unless a higher floor is configured.
+func (d *NASBlockStorageDriver) getMinVolumeSizeBytes() (uint64, error) {
+	if d.Config.MinimumVolumeSize == "" {
+		return MinimumSubvolumeSizeBytes, nil
 	}
-	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Rename")
-	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Rename")
 
-	// Rename is only needed for the import workflow, and we aren't currently renaming the
-	// ANF subvolume when importing, so do nothing here lest we set the subvolume name incorrectly
-	// during an import failure cleanup.
-	return nil
+	minVolumeSize, err := utils.ConvertSizeToBytes(d.Config.MinimumVolumeSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for minimumVolumeSize: %v", err)
+	}
+	minVolumeSizeBytes, err := strconv.ParseUint(minVolumeSize, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for minimumVolumeSize: %v", err)
+	}
+	if minVolumeSizeBytes < MinimumSubvolumeSizeBytes {
+		return 0, fmt.Errorf("minimumVolumeSize %s is below the ANF subvolume minimum of %d bytes",
+			d.Config.MinimumVolumeSize, MinimumSubvolumeSizeBytes)
+	}
+
+	return minVolumeSizeBytes, nil
 }
 
-// waitForSubvolumeCreate waits for volume creation to complete by reaching the Available state.  If the
-// volume reaches a terminal state (Error), the volume is deleted.  If the wait times out and the volume
-// is still creating, a VolumeCreatingError is returned so the caller may try again.
+// checkMinVolumeSize ensures a requested size is not below the backend's minimum volume size.
+func (d *NASBlockStorageDriver) checkMinVolumeSize(sizeBytes uint64) error {
+	minVolumeSizeBytes, err := d.getMinVolumeSizeBytes()
+	if err != nil {
+		return err
+	}
+
+	return drivers.CheckMinVolumeSize(sizeBytes, minVolumeSizeBytes)
+}
+
+// getMaxVolumeSizeBytes returns the backend's absolute maximum volume size in bytes, or zero if none is configured.
+func (d *NASBlockStorageDriver) getMaxVolumeSizeBytes() (uint64, error) {
+	if d.Config.MaxVolumeSize == "" {
+		return 0, nil
+	}
+
+	maxVolumeSize, err := utils.ConvertSizeToBytes(d.Config.MaxVolumeSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for maxVolumeSize: %v", err)
+	}
+	maxVolumeSizeBytes, err := strconv.ParseUint(maxVolumeSize, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value for maxVolumeSize: %v", err)
+	}
+
+	return maxVolumeSizeBytes, nil
+}
+
+// checkMaxVolumeSize ensures a requested size does not exceed the backend's absolute maximum volume size (if any).
+// Unlike limitVolumeSize, which is a soft per-volume limit, this is a hard bound on every create, clone, and resize.
+func (d *NASBlockStorageDriver) checkMaxVolumeSize(sizeBytes uint64) error {
+	maxVolumeSizeBytes, err := d.getMaxVolumeSizeBytes()
+	if err != nil {
+		return err
+	}
+
+	if maxVolumeSizeBytes > 0 && sizeBytes > maxVolumeSizeBytes {
+		return errors.UnsupportedCapacityRangeError(fmt.Errorf(
+			"requested size %d exceeds backend maximum volume size %d", sizeBytes, maxVolumeSizeBytes))
+	}
+
+	return nil
+}
+
+// sizeLogUnits are the binary units used to report sizes in log messages, in ascending order.
+var sizeLogUnits = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+
+// formatSizeForLog returns a human-readable representation of a size in bytes, such as "20 GiB" or "1.50 TiB".
+func formatSizeForLog(sizeBytes uint64) string {
+	size := float64(sizeBytes)
+	unit := 0
+	for size >= 1024 && unit < len(sizeLogUnits)-1 {
+		size /= 1024
+		unit++
+	}
+
+	if size == math.Trunc(size) {
+		return fmt.Sprintf("%d %s", uint64(size), sizeLogUnits[unit])
+	}
+	return fmt.Sprintf("%.2f %s", size, sizeLogUnits[unit])
+}
+
+// addSizeLogFields adds a human-readable size to the supplied log fields under key, and the raw byte value
+// under key + "Bytes" for machine parsing.
+func addSizeLogFields(fields LogFields, key string, sizeBytes uint64) LogFields {
+	fields[key] = formatSizeForLog(sizeBytes)
+	fields[key+"Bytes"] = sizeBytes
+	return fields
+}
+
+// getCloneSize returns the size with which a clone should be created.  A clone normally inherits the size of
+// its source, but if the clone's volume config requests a larger size, the clone is created at that size instead.
+// Requests smaller than the source are rejected.
+func (d *NASBlockStorageDriver) getCloneSize(
+	ctx context.Context, sourceVolConfig, volConfig *storage.VolumeConfig, sourceSubvolume *api.Subvolume,
+) (int64, error) {
+	cloneSize := sourceSubvolume.Size
+
+	if volConfig.Size == "" {
+		return cloneSize, nil
+	}
+
+	requestedSize, err := utils.ConvertSizeToBytes(volConfig.Size)
+	if err != nil {
+		return 0, fmt.Errorf("could not convert clone size %s: %v", volConfig.Size, err)
+	}
+	requestedSizeBytes, err := strconv.ParseUint(requestedSize, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v is an invalid clone size: %v", volConfig.Size, err)
+	}
+	if requestedSizeBytes == 0 {
+		return cloneSize, nil
+	}
+
+	// The subvolume is not fetched with metadata, so its size may be unknown; fall back to the source config.
+	sourceSizeBytes := uint64(sourceSubvolume.Size)
+	if sourceSizeBytes == 0 && sourceVolConfig.Size != "" {
+		if sourceSizeBytes, err = strconv.ParseUint(sourceVolConfig.Size, 10, 64); err != nil {
+			return 0, fmt.Errorf("%v is an invalid source volume size: %v", sourceVolConfig.Size, err)
+		}
+	}
+	if requestedSizeBytes < sourceSizeBytes {
+		return 0, fmt.Errorf("requested clone size %d is less than source volume size %d", requestedSizeBytes,
+			sourceSizeBytes)
+	}
+
+	// If the source size is still unknown, honor the requested size rather than letting the clone come out
+	// at an unknown (possibly zero) size.
+	if requestedSizeBytes > sourceSizeBytes {
+		if _, _, err = drivers.CheckVolumeSizeLimits(ctx, requestedSizeBytes,
+			d.Config.CommonStorageDriverConfig); err != nil {
+			return 0, err
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"sourceSize": sourceSizeBytes,
+			"cloneSize":  requestedSizeBytes,
+		}).Debug("Creating clone larger than its source.")
+
+		cloneSize = int64(requestedSizeBytes)
+	}
+
+	return cloneSize, nil
+}
+
+// Import finds an existing subvolume and makes it available for containers. If ImportNotManaged is false, the
+// subvolume is fully brought under Trident's management.
+func (d *NASBlockStorageDriver) Import(
+	ctx context.Context, volConfig *storage.VolumeConfig, originalName string,
+) error {
+	fields := LogFields{
+		"Method":       "Import",
+		"Type":         "NASBlockStorageDriver",
+		"originalName": originalName,
+		"newName":      volConfig.InternalName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Import")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Import")
+
+	// Make sure the original name is in an acceptable format
+	if err := d.validateCreationToken(originalName); err != nil {
+		return err
+	}
+
+	// Ensure subvolume is actually a subvolume, and it isn't a "snapshot" subvolume
+	if !d.isFileValidVolume(ctx, originalName) {
+		return fmt.Errorf("ineligible for import; subvolume %s is a snapshot subvolume", originalName)
+	}
+
+	subvolumeWithMetadata, err := d.SDK.SubvolumeByCreationToken(ctx, originalName, d.getAllFilePoolVolumes(), true)
+	if err != nil {
+		return fmt.Errorf("could not find subvolume %s; %v", originalName, err)
+	}
+
+	if err = d.checkMinVolumeSize(uint64(subvolumeWithMetadata.Size)); err != nil {
+		return fmt.Errorf("size error; %v", err)
+	}
+
+	volConfig.Size = strconv.FormatInt(subvolumeWithMetadata.Size, 10)
+
+	// The ANF subvolume creation token cannot be changed, so use it as the internal name unless the backend
+	// allows managed subvolumes to be renamed (by cloning) to Trident's naming scheme on import
+	if volConfig.ImportNotManaged || !d.Config.AllowRenameOnImport || volConfig.InternalName == "" ||
+		volConfig.InternalName == originalName {
+		volConfig.InternalName = originalName
+	} else {
+		renamedSubvolume, err := d.renameSubvolume(ctx, subvolumeWithMetadata, volConfig.InternalName)
+		if err != nil {
+			return fmt.Errorf("could not rename subvolume %s to %s; %v", originalName, volConfig.InternalName, err)
+		}
+		subvolumeWithMetadata = renamedSubvolume
+	}
+
+	// Always save the ID so we can find the volume efficiently later
+	volConfig.InternalID = subvolumeWithMetadata.ID
+
+	// A subvolume has the service level of its parent volume.  It is only informational here, so the import
+	// proceeds without it if the parent volume cannot be read.
+	filePoolVolume := api.CreateVolumeFullName(subvolumeWithMetadata.ResourceGroup,
+		subvolumeWithMetadata.NetAppAccount, subvolumeWithMetadata.CapacityPool, subvolumeWithMetadata.Volume)
+	if volume, err := d.getFilePoolVolume(ctx, filePoolVolume); err != nil {
+		Logc(ctx).WithField("filePoolVolume", filePoolVolume).WithError(err).Warning(
+			"Could not determine service level of imported subvolume.")
+	} else {
+		volConfig.ServiceLevel = volume.ServiceLevel
+	}
+
+	return nil
+}
+
+// Rename renames a subvolume.  Rename is only needed for the import workflow, so unless the backend allows
+// renaming on import, this does nothing lest we set the subvolume name incorrectly during an import failure cleanup.
+func (d *NASBlockStorageDriver) Rename(ctx context.Context, name, newName string) error {
+	fields := LogFields{
+		"Method":  "Rename",
+		"Type":    "NASBlockStorageDriver",
+		"name":    name,
+		"newName": newName,
+	}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Rename")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Rename")
+
+	if !d.Config.AllowRenameOnImport || name == newName {
+		return nil
+	}
+
+	subvolume, err := d.GetVolume(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.renameSubvolume(ctx, subvolume, newName)
+	return err
+}
+
+// renameSubvolume gives a subvolume a new creation token.  Creation tokens are immutable, so the subvolume is
+// cloned to the new name and the original is deleted.  If the clone cannot be created, or the original cannot be
+// deleted, the clone is removed and the original is left intact.
+func (d *NASBlockStorageDriver) renameSubvolume(
+	ctx context.Context, subvolume *api.Subvolume, newName string,
+) (*api.Subvolume, error) {
+	if err := d.validateCreationToken(newName); err != nil {
+		return nil, err
+	}
+
+	if d.Config.DryRun {
+		return nil, d.dryRunModify(ctx, "Rename", subvolume.Name)
+	}
+
+	subvolumeCreateRequest := &api.SubvolumeCreateRequest{
+		CreationToken: newName,
+		Volume: api.CreateVolumeFullName(subvolume.ResourceGroup, subvolume.NetAppAccount,
+			subvolume.CapacityPool, subvolume.Volume),
+		Size:   subvolume.Size,
+		Parent: subvolume.Name,
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"subvolume": subvolume.Name,
+		"newName":   newName,
+	}).Debug("Renaming subvolume by cloning.")
+
+	renamedSubvolume, _, err := d.SDK.CreateSubvolume(ctx, subvolumeCreateRequest)
+	if err != nil {
+		return nil, fmt.Errorf("could not create subvolume %s; %v", newName, err)
+	}
+
+	if _, err = d.SDK.WaitForSubvolumeState(ctx, renamedSubvolume, api.StateAvailable, []string{api.StateError},
+		d.volumeCreateTimeout); err != nil {
+		d.rollBackRename(ctx, renamedSubvolume)
+		return nil, fmt.Errorf("subvolume %s was not created; %v", newName, err)
+	}
+
+	if _, err = d.SDK.DeleteSubvolume(ctx, subvolume); err != nil {
+		d.rollBackRename(ctx, renamedSubvolume)
+		return nil, fmt.Errorf("could not delete original subvolume %s; %v", subvolume.Name, err)
+	}
+
+	// Once the delete has been accepted the original is going away, so the renamed subvolume must be kept
+	if _, err = d.SDK.WaitForSubvolumeState(ctx, subvolume, api.StateDeleted, []string{api.StateError},
+		d.deleteTimeout); err != nil {
+		Logc(ctx).WithField("subvolume", subvolume.Name).WithError(err).Warning(
+			"Original subvolume was not deleted after rename and may need to be manually deleted.")
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"subvolume": subvolume.Name,
+		"newName":   newName,
+	}).Info("Subvolume renamed.")
+
+	return renamedSubvolume, nil
+}
+
+// rollBackRename deletes the clone created by a failed rename, leaving the original subvolume in place.
+func (d *NASBlockStorageDriver) rollBackRename(ctx context.Context, renamedSubvolume *api.Subvolume) {
+	if err := d.deleteSubvolume(ctx, renamedSubvolume, d.deleteTimeout); err != nil {
+		Logc(ctx).WithField("subvolume", renamedSubvolume.Name).WithError(err).Error(
+			"Subvolume could not be cleaned up after a failed rename and must be manually deleted.")
+	}
+}
+
+// resolveCreatedSubvolumeID fills in the ID of a newly created subvolume if Azure did not return one, since the
+// ID is recorded in the volume config and is needed to track the subvolume's state.  If the subvolume cannot be
+// found yet, a VolumeCreatingError is returned so that the create is retried and picks up the existing subvolume.
+func (d *NASBlockStorageDriver) resolveCreatedSubvolumeID(
+	ctx context.Context, subvolume *api.Subvolume, creationToken, filePoolVolume string,
+) error {
+	if subvolume.ID != "" {
+		return nil
+	}
+
+	Logc(ctx).WithField("subvolume", creationToken).Debug("Subvolume created without an ID, looking it up.")
+
+	extantSubvolume, err := d.SDK.SubvolumeByCreationToken(ctx, creationToken, []string{filePoolVolume}, false)
+	if err != nil {
+		return errors.VolumeCreatingError(fmt.Sprintf("could not find ID of subvolume %s; %v", creationToken, err))
+	}
+	if extantSubvolume.ID == "" {
+		return errors.VolumeCreatingError(fmt.Sprintf("subvolume %s has no ID yet", creationToken))
+	}
+
+	subvolume.ID = extantSubvolume.ID
+
+	return nil
+}
+
+// waitForSubvolumeCreate waits up to the specified timeout for volume creation to complete by reaching the
+// Available state.  If the volume reaches a terminal state (Error), the volume is deleted unless
+// retainFailedVolumes is set.  If the wait times out and the volume is still creating, a VolumeCreatingError
+// is returned so the caller may try again.
 func (d *NASBlockStorageDriver) waitForSubvolumeCreate(
 	ctx context.Context, subvolume *api.Subvolume,
-	poller api.PollerResponse, operation Operation, handleErrorInFollowup bool,
+	poller api.PollerResponse, operation Operation, handleErrorInFollowup bool, timeout time.Duration,
 ) error {
 	var pollForError bool
 
+	waitStartTime := time.Now()
 	state, err := d.SDK.WaitForSubvolumeState(
-		ctx, subvolume, api.StateAvailable, []string{api.StateError}, d.volumeCreateTimeout)
+		ctx, subvolume, api.StateAvailable, []string{api.StateError}, timeout)
+	recordSubvolumeCreateWait(d.BackendName(), operation.String(), waitStartTime)
 	if err != nil {
 
 		logFields := LogFields{"subvolume": subvolume}
@@ -1004,16 +2338,18 @@ func (d *NASBlockStorageDriver) waitForSubvolumeCreate(
 		case api.StateDeleting:
 			// Wait for deletion to complete
 			_, errDelete := d.SDK.WaitForSubvolumeState(
-				ctx, subvolume, api.StateDeleted, []string{api.StateError}, d.defaultTimeout())
+				ctx, subvolume, api.StateDeleted, []string{api.StateError}, d.deleteTimeout)
 			if errDelete != nil {
 				Logc(ctx).WithFields(logFields).WithError(errDelete).Error(
 					"Subvolume could not be cleaned up and must be manually deleted.")
 			}
 
 		case api.StateError:
-			// Delete a failed volume
-			_, errDelete := d.SDK.DeleteSubvolume(ctx, subvolume)
-			if errDelete != nil {
+			// Delete a failed volume, unless the backend is configured to keep it for troubleshooting
+			if d.Config.RetainFailedVolumes {
+				Logc(ctx).WithFields(logFields).Warning(
+					"Subvolume creation failed; retaining subvolume, which must be manually deleted.")
+			} else if _, errDelete := d.SDK.DeleteSubvolume(ctx, subvolume); errDelete != nil {
 				Logc(ctx).WithFields(logFields).WithError(errDelete).Error(
 					"Subvolume could not be cleaned up and must be manually deleted.")
 			} else {
@@ -1023,7 +2359,9 @@ func (d *NASBlockStorageDriver) waitForSubvolumeCreate(
 			pollForError = true
 
 		case api.StateMoving, api.StateReverting:
-			fallthrough
+			// These are transient states of an in-flight operation, so keep waiting rather than giving up
+			Logc(ctx).WithFields(logFields).Debugf("Subvolume is in %s state.", state)
+			return errors.VolumeCreatingError(err.Error())
 
 		default:
 			Logc(ctx).WithFields(logFields).Errorf("unexpected subvolume state %s found for subvolume", state)
@@ -1031,14 +2369,14 @@ func (d *NASBlockStorageDriver) waitForSubvolumeCreate(
 		}
 	}
 
-	// If here, it means volume might be successful, or in deleting, error, moving or unexpected state,
+	// If here, it means volume might be successful, or in deleting, error or unexpected state,
 	// and not in creating state, so it should be safe to remove it from futures cache
 	pollerKey := PollerKey{
 		ID:        subvolume.Name,
 		Operation: operation,
 	}
 
-	delete(pollerResponseCache, pollerKey)
+	d.pollers.Delete(pollerKey)
 
 	if pollForError && poller != nil {
 		if err != nil && state == api.StateError {
@@ -1046,6 +2384,10 @@ func (d *NASBlockStorageDriver) waitForSubvolumeCreate(
 		} else {
 			return poller.Result(ctx)
 		}
+	} else if pollForError && state != api.StateError {
+		// Without a poller, as when the create was started before a restart, only the subvolume itself can tell
+		// whether the create is still in progress
+		return d.checkSubvolumeCreateState(ctx, subvolume)
 	}
 
 	// If followup exists to handler error, then return nil
@@ -1056,11 +2398,34 @@ func (d *NASBlockStorageDriver) waitForSubvolumeCreate(
 	return err
 }
 
+// checkSubvolumeCreateState reads a subvolume's current state to learn the outcome of a create whose poller is
+// unavailable.  It returns nil if the subvolume is available and a VolumeCreatingError if the create is still in
+// progress or the subvolume could not be read.
+func (d *NASBlockStorageDriver) checkSubvolumeCreateState(ctx context.Context, subvolume *api.Subvolume) error {
+	current, err := d.SDK.SubvolumeByID(ctx, subvolume.ID, false)
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			return fmt.Errorf("subvolume %s no longer exists", subvolume.Name)
+		}
+		return errors.VolumeCreatingError(fmt.Sprintf("could not read state of subvolume %s; %v", subvolume.Name,
+			err))
+	}
+
+	switch current.ProvisioningState {
+	case api.StateAvailable:
+		return nil
+	case api.StateAccepted, api.StateCreating, api.StateMoving, api.StateReverting:
+		return errors.VolumeCreatingError(fmt.Sprintf("subvolume %s is in %s state", subvolume.Name,
+			current.ProvisioningState))
+	default:
+		return fmt.Errorf("subvolume %s is in unexpected state %s", subvolume.Name, current.ProvisioningState)
+	}
+}
+
 // Destroy deletes a volume.
-func (d *NASBlockStorageDriver) Destroy(ctx context.Context, volConfig *storage.VolumeConfig) error {
+func (d *NASBlockStorageDriver) Destroy(ctx context.Context, volConfig *storage.VolumeConfig) (err error) {
 	var extantSubvolume *api.Subvolume
 	var subvolumeExists bool
-	var err error
 
 	creationToken := volConfig.InternalName
 
@@ -1072,6 +2437,9 @@ func (d *NASBlockStorageDriver) Destroy(ctx context.Context, volConfig *storage.
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Destroy")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Destroy")
 
+	startTime := time.Now()
+	defer func() { recordSubvolumeOperation(d.BackendName(), "destroy", startTime, err) }()
+
 	// In case where subvolume creation fails it may not contain an internalID, so clean it up using creation token
 	if volConfig.InternalID == "" {
 		subvolumeExists, extantSubvolume, err = d.SDK.SubvolumeExists(ctx, volConfig, d.getAllFilePoolVolumes())
@@ -1084,8 +2452,13 @@ func (d *NASBlockStorageDriver) Destroy(ctx context.Context, volConfig *storage.
 			return nil
 		} else if extantSubvolume.ProvisioningState == api.StateDeleting {
 			// This is a retry, so give it more time before giving up again.
-			_, err = d.SDK.WaitForSubvolumeState(
-				ctx, extantSubvolume, api.StateDeleted, []string{api.StateError}, d.volumeCreateTimeout)
+			var state string
+			state, err = d.SDK.WaitForSubvolumeState(
+				ctx, extantSubvolume, api.StateDeleted, []string{api.StateError}, d.deleteTimeout)
+			if err != nil && state == api.StateError {
+				return fmt.Errorf("subvolume %s entered an error state while deleting and must be manually "+
+					"deleted; %v", creationToken, err)
+			}
 			return err
 		}
 	} else {
@@ -1104,7 +2477,79 @@ func (d *NASBlockStorageDriver) Destroy(ctx context.Context, volConfig *storage.
 		}
 	}
 
-	return d.deleteSubvolume(extantSubvolume)
+	if d.Config.DryRun {
+		return d.dryRunDelete(ctx, "Destroy", extantSubvolume)
+	}
+
+	if err = d.volumeRateLimiters.Wait(ctx, api.CreateVolumeFullName(extantSubvolume.ResourceGroup,
+		extantSubvolume.NetAppAccount, extantSubvolume.CapacityPool, extantSubvolume.Volume)); err != nil {
+		return err
+	}
+
+	// A subvolume whose creation failed has no snapshots, so only a fully created one is checked
+	if volConfig.InternalID != "" {
+		if err = d.deleteDependentSnapshots(ctx, volConfig, extantSubvolume); err != nil {
+			return err
+		}
+	}
+
+	return d.deleteSubvolume(ctx, extantSubvolume, d.deleteTimeout)
+}
+
+// deleteDependentSnapshots looks for snapshots of a subvolume that is about to be destroyed.  Snapshot subvolumes
+// are independent copies, so destroying their source would leave them orphaned.  Unless the backend is configured
+// to force destroys, an error naming the snapshots is returned; otherwise the snapshots are deleted first.  If the
+// snapshots cannot be listed, the subvolume is not destroyed either way.
+func (d *NASBlockStorageDriver) deleteDependentSnapshots(
+	ctx context.Context, volConfig *storage.VolumeConfig, subvolume *api.Subvolume,
+) error {
+	snapshots, err := d.listSnapshots(ctx, volConfig, subvolume)
+	if err != nil {
+		return fmt.Errorf("could not check subvolume %s for snapshots; %v", subvolume.Name, err)
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	snapshotNames := make([]string, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		snapshotNames = append(snapshotNames, snapshot.Config.InternalName)
+	}
+	sort.Strings(snapshotNames)
+
+	if !d.Config.ForceDestroy {
+		return fmt.Errorf("subvolume %s has snapshots %s; delete them first, or set forceDestroy to delete "+
+			"them with the subvolume", subvolume.Name, strings.Join(snapshotNames, ", "))
+	}
+
+	subscription, _, _, _, _, _, _, err := api.ParseSubvolumeID(subvolume.ID)
+	if err != nil {
+		return fmt.Errorf("could not parse ID '%s' of subvolume %s; %v", subvolume.ID, subvolume.Name, err)
+	}
+
+	for _, snapshotName := range snapshotNames {
+		// ID of the snapshot subvolume should have all the same attributes as the source volume except the name
+		snapshotSubvolume := &api.Subvolume{
+			ID: api.CreateSubvolumeID(subscription, subvolume.ResourceGroup, subvolume.NetAppAccount,
+				subvolume.CapacityPool, subvolume.Volume, snapshotName),
+			ResourceGroup: subvolume.ResourceGroup,
+			NetAppAccount: subvolume.NetAppAccount,
+			CapacityPool:  subvolume.CapacityPool,
+			Volume:        subvolume.Volume,
+			Name:          snapshotName,
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"subvolume": subvolume.Name,
+			"snapshot":  snapshotName,
+		}).Info("Deleting snapshot of destroyed subvolume.")
+
+		if err = d.deleteSubvolume(ctx, snapshotSubvolume, d.snapshotTimeout); err != nil {
+			return fmt.Errorf("could not delete snapshot %s of subvolume %s; %v", snapshotName, subvolume.Name, err)
+		}
+	}
+
+	return nil
 }
 
 // Publish the volume to the host specified in publishInfo.  This method may or may not be running on the host
@@ -1124,10 +2569,18 @@ func (d *NASBlockStorageDriver) Publish(
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Publish")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Publish")
 
-	// Get the subvolume's parent ANF volume
-	volume, err := d.SDK.SubvolumeParentVolume(ctx, volConfig)
-	if err != nil {
-		return fmt.Errorf("could not find subvolume's ('%s') parent volume: %v", creationToken, err)
+	if err := validateFileSystemType(volConfig.FileSystem); err != nil {
+		return err
+	}
+
+	// Get the subvolume's parent ANF volume, preferring a recent lookup to spare the Azure API during publish storms
+	volume, ok := d.getCachedParentVolume(volConfig)
+	if !ok {
+		var err error
+		if volume, err = d.SDK.SubvolumeParentVolume(ctx, volConfig); err != nil {
+			return fmt.Errorf("could not find subvolume's ('%s') parent volume: %v", creationToken, err)
+		}
+		d.cacheParentVolume(ctx, volume)
 	}
 
 	if len(volume.MountTargets) == 0 {
@@ -1135,10 +2588,17 @@ func (d *NASBlockStorageDriver) Publish(
 	}
 
 	// Set the correct NFS mount option based on volume's protocol
-	NFSMountOption := fmt.Sprintf("vers=%s", strings.TrimPrefix(volume.ProtocolTypes[0],
-		api.ProtocolTypeNFSPrefix))
+	NFSMountOption, err := d.getNFSVersionMountOption(volume)
+	if err != nil {
+		return err
+	}
 	mountOptions := utils.SetNFSVersionMountOptions(d.Config.NfsMountOptions, NFSMountOption)
 
+	// Ensure the parent volume supports the requested security flavors
+	if err = validateSecurityFlavors(volume, getSecurityFlavors(mountOptions)); err != nil {
+		return err
+	}
+
 	// Subvolume mount options can only be specified via tha storage class.
 	subvolumeMountOptions := ""
 	if volConfig.MountOptions != "" {
@@ -1161,17 +2621,52 @@ func (d *NASBlockStorageDriver) Publish(
 	publishInfo.NfsPath = "/" + volume.CreationToken
 	publishInfo.NfsUniqueID = d.createFilePoolVolumePathHash(volume)
 	publishInfo.SubvolumeName = volConfig.InternalName
-	publishInfo.MountOptions = strings.TrimPrefix(mountOptions, "-o ")
-	publishInfo.SubvolumeMountOptions = strings.TrimPrefix(subvolumeMountOptions, "-o ")
+	publishInfo.MountOptions = trimMountOptionsFlag(mountOptions)
+	publishInfo.SubvolumeMountOptions = trimMountOptionsFlag(subvolumeMountOptions)
 	publishInfo.FilesystemType = fsType
+	publishInfo.SubvolumeUnixPermissions = volConfig.UnixPermissions
 
 	return nil
 }
 
+// validateFileSystemType checks that a volume's filesystem type, which CreateFollowup prefixes with "nfs/", is one
+// the node can format and mount a subvolume with.  An empty type selects the default.
+func validateFileSystemType(fileSystem string) error {
+	fsType := strings.TrimPrefix(fileSystem, "nfs/")
+	if fsType == "" || utils.SliceContainsString(supportedFileSystemTypes, fsType) {
+		return nil
+	}
+
+	return fmt.Errorf("filesystem type '%s' is not supported for ANF subvolumes; must be one of %s", fsType,
+		strings.Join(supportedFileSystemTypes, ", "))
+}
+
+// trimMountOptionsFlag returns a mount options string as a plain comma-separated list, without any leading "-o"
+// flag and regardless of the whitespace around it.
+func trimMountOptionsFlag(mountOptions string) string {
+	mountOptions = strings.TrimSpace(mountOptions)
+	return strings.TrimSpace(strings.TrimPrefix(mountOptions, "-o"))
+}
+
 // CanSnapshot determines whether a snapshot as specified in the provided snapshot config may be taken.
+// If the backend limits the number of snapshots per volume, a volume already at the limit may not be snapshotted.
 func (d *NASBlockStorageDriver) CanSnapshot(
-	_ context.Context, _ *storage.SnapshotConfig, _ *storage.VolumeConfig,
+	ctx context.Context, _ *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
 ) error {
+	if d.maxSnapshotsPerVolume == 0 {
+		return nil
+	}
+
+	snapshots, err := d.GetSnapshots(ctx, volConfig)
+	if err != nil {
+		return fmt.Errorf("could not count snapshots of volume %s; %v", volConfig.Name, err)
+	}
+
+	if len(snapshots) >= d.maxSnapshotsPerVolume {
+		return errors.MaxLimitReachedError(fmt.Sprintf("volume %s already has %d snapshots; the backend allows "+
+			"at most %d snapshots per volume", volConfig.Name, len(snapshots), d.maxSnapshotsPerVolume))
+	}
+
 	return nil
 }
 
@@ -1192,55 +2687,106 @@ func (d *NASBlockStorageDriver) GetSnapshot(
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> GetSnapshot")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< GetSnapshot")
 
-	// Get the source subvolume
-	sourceSubvolumeExists, sourceSubvolume, err := d.SDK.SubvolumeExistsByID(ctx, volConfig.InternalID)
-	if err != nil {
-		return nil, fmt.Errorf("could not find source subvolume '%s'; %v", volConfig.InternalID, err)
-	}
-	if !sourceSubvolumeExists {
-		return nil, fmt.Errorf("source subvolume '%s' does not exist", volConfig.Name)
-	}
-
 	// For snapshot imports, creation token should be the internal name for the backend snapshot.
 	creationToken := snapConfig.InternalName
 
-	// Build the backend snapshot ID from the source volume and config internal snap name.
-	snapshotInternalID := api.CreateSubvolumeID(d.Config.SubscriptionID, sourceSubvolume.ResourceGroup,
-		sourceSubvolume.NetAppAccount, sourceSubvolume.CapacityPool, sourceSubvolume.Volume, creationToken)
-
-	snapshotExists, extantSubvolume, err := d.SDK.SubvolumeExistsByID(ctx, snapshotInternalID)
+	extantSubvolume, snapshotInternalID, err := d.getSnapshotByInternalName(ctx, volConfig, creationToken)
 	if err != nil {
-		return nil, fmt.Errorf("error checking for existing snapshot %s; %v", snapshotInternalID, err)
+		return nil, err
 	}
-	if !snapshotExists {
+	if extantSubvolume == nil {
 		return nil, nil
 	}
 
-	if extantSubvolume.ProvisioningState != api.StateAvailable {
-		return nil, fmt.Errorf("snapshot %s state is %s", creationToken, extantSubvolume.ProvisioningState)
+	// Report transient provisioning states so the orchestrator can poll rather than fail
+	var state storage.SnapshotState
+	switch extantSubvolume.ProvisioningState {
+	case api.StateCreating, api.StateAccepted:
+		state = storage.SnapshotStateCreating
+	case api.StateError:
+		return &storage.Snapshot{
+			Config:    snapConfig,
+			Created:   time.Time{}.UTC().Format(utils.TimestampFormat),
+			SizeBytes: extantSubvolume.Size,
+			State:     storage.SnapshotStateMissingBackend,
+		}, fmt.Errorf("snapshot %s state is %s", creationToken, extantSubvolume.ProvisioningState)
+	default:
+		state = storage.SnapshotStateOnline
 	}
 
 	Logc(ctx).WithFields(LogFields{
 		"snapshotName":         snapName,
 		"snapshotInternalName": creationToken,
 		"volumeName":           internalVolName,
+		"state":                state,
 	}).Debug("Found snapshot.")
 
+	// The creation timestamp is only available from the subvolume metadata, which is expensive to read,
+	// so fall back to a zero timestamp if the metadata cannot be read
+	created := time.Time{}
+	sizeBytes := extantSubvolume.Size
+	parentPath := extantSubvolume.ParentPath
+	if snapshotWithMetadata, err := d.SDK.SubvolumeByID(ctx, snapshotInternalID, true); err != nil {
+		Logc(ctx).WithField("snapshot", creationToken).WithError(err).Warning(
+			"Could not read snapshot metadata; creation time unknown.")
+	} else {
+		created = d.checkSnapshotCreationTime(ctx, creationToken, snapshotWithMetadata.Created)
+		if snapshotWithMetadata.Size > 0 {
+			sizeBytes = snapshotWithMetadata.Size
+		}
+		if parentPath == "" {
+			parentPath = snapshotWithMetadata.ParentPath
+		}
+	}
+
+	// A snapshot being imported may name any subvolume, so make sure it was copied from this volume
+	if !isSubvolumeParentPath(parentPath, volConfig.InternalName) {
+		return nil, errors.InvalidInputError(fmt.Sprintf("subvolume %s is not a snapshot of volume %s",
+			creationToken, volConfig.Name))
+	}
+
 	return &storage.Snapshot{
 		Config:    snapConfig,
-		Created:   time.Time{}.UTC().Format(utils.TimestampFormat),
-		SizeBytes: 0,
-		State:     storage.SnapshotStateOnline,
+		Created:   created.UTC().Format(utils.TimestampFormat),
+		SizeBytes: sizeBytes,
+		State:     state,
 	}, nil
 }
 
-// GetSnapshots returns the list of snapshots associated with the specified subvolume
+// getSnapshotByInternalName reads a volume's snapshot by its internal name.  A snapshot subvolume is created in the
+// same parent volume as its source, so its ID is built from the source's ID, and the snapshot is read directly rather
+// than found by listing the parent volume's subvolumes.  The snapshot's ID is also returned, and the snapshot is nil
+// if it does not exist.
+func (d *NASBlockStorageDriver) getSnapshotByInternalName(
+	ctx context.Context, volConfig *storage.VolumeConfig, internalName string,
+) (*api.Subvolume, string, error) {
+	subscription, resourceGroup, _, netappAccount, capacityPool, volume, _, err := api.ParseSubvolumeID(
+		volConfig.InternalID)
+	if err != nil {
+		return nil, "", fmt.Errorf("could not parse ID '%s' of source subvolume %s; %v", volConfig.InternalID,
+			volConfig.Name, err)
+	}
+
+	// ID of the snapshot subvolume should have all the same attributes as the source volume except the name
+	snapshotID := api.CreateSubvolumeID(subscription, resourceGroup, netappAccount, capacityPool, volume,
+		internalName)
+
+	snapshotExists, snapshot, err := d.SDK.SubvolumeExistsByID(ctx, snapshotID)
+	if err != nil {
+		return nil, "", fmt.Errorf("error checking for existing snapshot %s; %v", snapshotID, err)
+	}
+	if !snapshotExists {
+		return nil, snapshotID, nil
+	}
+
+	return snapshot, snapshotID, nil
+}
+
+// GetSnapshots returns the list of snapshots associated with the specified subvolume
 func (d *NASBlockStorageDriver) GetSnapshots(
 	ctx context.Context, volConfig *storage.VolumeConfig,
 ) ([]*storage.Snapshot, error) {
 	internalVolName := volConfig.InternalName
-	externalVolName := volConfig.Name
-	prefix := *d.Config.StoragePrefix
 
 	fields := LogFields{
 		"Method":     "GetSnapshots",
@@ -1256,51 +2802,159 @@ func (d *NASBlockStorageDriver) GetSnapshots(
 		return nil, fmt.Errorf("could not find source subvolume '%s'; %v", volConfig.InternalID, err)
 	}
 
+	return d.listSnapshots(ctx, volConfig, sourceSubvolume)
+}
+
+// listSnapshots returns the snapshots of a source subvolume, found among the subvolumes of its parent volume.
+func (d *NASBlockStorageDriver) listSnapshots(
+	ctx context.Context, volConfig *storage.VolumeConfig, sourceSubvolume *api.Subvolume,
+) ([]*storage.Snapshot, error) {
 	// Fetch list of all the subvolumes from parent volume of the above volConfig
-	subvolumes, err := d.SDK.Subvolumes(ctx, []string{
-		api.CreateVolumeFullName(sourceSubvolume.ResourceGroup,
-			sourceSubvolume.NetAppAccount, sourceSubvolume.CapacityPool, sourceSubvolume.Volume),
-	})
+	parentVolumeName := api.CreateVolumeFullName(sourceSubvolume.ResourceGroup,
+		sourceSubvolume.NetAppAccount, sourceSubvolume.CapacityPool, sourceSubvolume.Volume)
+	subvolumes, err := d.SDK.Subvolumes(ctx, []string{parentVolumeName})
 	if err != nil {
 		return nil, err
 	}
+	if err = api.CheckSubvolumesListed(len(*subvolumes), d.maxSubvolumesListed, parentVolumeName); err != nil {
+		return nil, err
+	}
 
-	snapshots := make([]*storage.Snapshot, 0)
-
-	for _, subvolume := range *subvolumes {
+	snapshotSuffixes := d.helper.GetSnapshotSuffixes(volConfig.Name)
+	matched := d.matchSnapshots(*subvolumes, func(subvolume *api.Subvolume) *storage.Snapshot {
+		return d.snapshotFromSubvolume(ctx, subvolume, sourceSubvolume.Name, snapshotSuffixes, volConfig)
+	})
 
-		// Filter out subvolume without the prefix (pass all if prefix is empty)
-		if !strings.HasPrefix(subvolume.Name, prefix) {
-			continue
+	snapshots := make([]*storage.Snapshot, 0, len(matched))
+	for _, snapshot := range matched {
+		if snapshot != nil {
+			snapshots = append(snapshots, snapshot)
 		}
+	}
 
-		if !d.helper.IsValidSnapshotInternalName(subvolume.Name) {
-			continue
+	return snapshots, nil
+}
+
+// matchSnapshots applies match to each subvolume and returns the results in the same order as the subvolumes.
+// With more than one snapshot listing worker configured, the subvolumes are split among that many goroutines.
+func (d *NASBlockStorageDriver) matchSnapshots(
+	subvolumes []*api.Subvolume, match func(*api.Subvolume) *storage.Snapshot,
+) []*storage.Snapshot {
+	results := make([]*storage.Snapshot, len(subvolumes))
+
+	workers := d.snapshotListingWorkers
+	if workers > len(subvolumes) {
+		workers = len(subvolumes)
+	}
+
+	if workers <= 1 {
+		for i, subvolume := range subvolumes {
+			results[i] = match(subvolume)
 		}
+		return results
+	}
 
-		// TODO: Filter out subvolumes whose parentPath does not match parent subvolume's internal name.
-		//       Unfortunately SDK does not return this information, thus using below alternative.
-		if d.helper.GetSnapshotSuffixFromSnapshotInternalName(subvolume.Name) != d.helper.GetSnapshotSuffix(externalVolName) {
-			continue
+	// Each worker takes a contiguous range and writes only its own slots, so no locking is needed
+	chunkSize := (len(subvolumes) + workers - 1) / workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(subvolumes); start += chunkSize {
+		end := start + chunkSize
+		if end > len(subvolumes) {
+			end = len(subvolumes)
 		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				results[i] = match(subvolumes[i])
+			}
+		}(start, end)
+	}
+	wg.Wait()
 
-		snapName := d.helper.GetSnapshotNameFromSnapInternalName(subvolume.Name)
-		snapshot := &storage.Snapshot{
-			Config: &storage.SnapshotConfig{
-				Version:            tridentconfig.OrchestratorAPIVersion,
-				Name:               snapName,
-				InternalName:       subvolume.Name,
-				VolumeName:         externalVolName,
-				VolumeInternalName: internalVolName,
-			},
-			Created:   time.Time{}.UTC().Format(utils.TimestampFormat),
-			SizeBytes: 0,
-			State:     storage.SnapshotStateOnline,
+	return results
+}
+
+// snapshotFromSubvolume returns the snapshot represented by a subvolume, or nil if the subvolume is not a snapshot
+// of the named source subvolume.
+func (d *NASBlockStorageDriver) snapshotFromSubvolume(
+	ctx context.Context, subvolume *api.Subvolume, sourceSubvolumeName string, snapshotSuffixes []string,
+	volConfig *storage.VolumeConfig,
+) *storage.Snapshot {
+	// Filter out subvolume without the prefix (pass all if prefix is empty)
+	if !d.hasStoragePrefix(subvolume.Name) {
+		return nil
+	}
+
+	if !d.helper.IsValidSnapshotInternalName(subvolume.Name) {
+		return nil
+	}
+
+	// The suffix is a cheap first filter, but several volumes may share a short suffix
+	if !utils.SliceContainsString(snapshotSuffixes, d.helper.GetSnapshotSuffixFromSnapshotInternalName(subvolume.Name)) {
+		return nil
+	}
+
+	// The creation timestamp (and often the size) is only available from each snapshot's metadata, which is
+	// expensive to read, so it is only read if the backend asks for detailed snapshot listings
+	created := time.Time{}
+	sizeBytes := subvolume.Size
+	parentPath := subvolume.ParentPath
+	if d.Config.DetailedSnapshotListing {
+		var metadataParentPath string
+		created, sizeBytes, metadataParentPath = d.getSnapshotDetails(ctx, subvolume)
+		if parentPath == "" {
+			parentPath = metadataParentPath
 		}
-		snapshots = append(snapshots, snapshot)
 	}
 
-	return snapshots, nil
+	// Where the parent path is known, it tells precisely whether the snapshot was copied from this subvolume
+	if !isSubvolumeParentPath(parentPath, sourceSubvolumeName) {
+		return nil
+	}
+
+	return &storage.Snapshot{
+		Config: &storage.SnapshotConfig{
+			Version:            tridentconfig.OrchestratorAPIVersion,
+			Name:               d.helper.GetSnapshotNameFromSnapInternalName(subvolume.Name),
+			InternalName:       subvolume.Name,
+			VolumeName:         volConfig.Name,
+			VolumeInternalName: volConfig.InternalName,
+		},
+		Created:   created.UTC().Format(utils.TimestampFormat),
+		SizeBytes: sizeBytes,
+		State:     storage.SnapshotStateOnline,
+	}
+}
+
+// getSnapshotDetails reads a snapshot subvolume's metadata and returns its creation time, size, and parent path.
+// If the metadata cannot be read, a zero creation time, the listed size, and the listed parent path are returned.
+func (d *NASBlockStorageDriver) getSnapshotDetails(
+	ctx context.Context, subvolume *api.Subvolume,
+) (time.Time, int64, string) {
+	snapshotWithMetadata, err := d.SDK.SubvolumeByID(ctx, subvolume.ID, true)
+	if err != nil {
+		Logc(ctx).WithField("snapshot", subvolume.Name).WithError(err).Warning(
+			"Could not read snapshot metadata; creation time unknown.")
+		return time.Time{}, subvolume.Size, subvolume.ParentPath
+	}
+
+	sizeBytes := subvolume.Size
+	if snapshotWithMetadata.Size > 0 {
+		sizeBytes = snapshotWithMetadata.Size
+	}
+
+	return d.checkSnapshotCreationTime(ctx, subvolume.Name, snapshotWithMetadata.Created), sizeBytes,
+		snapshotWithMetadata.ParentPath
+}
+
+// isSubvolumeParentPath reports whether a subvolume's parent path names the given subvolume.  An unknown (empty)
+// parent path cannot rule the subvolume out, so it matches.
+func isSubvolumeParentPath(parentPath, subvolumeName string) bool {
+	if parentPath == "" {
+		return true
+	}
+	return strings.TrimPrefix(parentPath, "/") == subvolumeName
 }
 
 // CreateSnapshot creates a snapshot for the given volume
@@ -1308,7 +2962,7 @@ func (d *NASBlockStorageDriver) GetSnapshots(
 // subvolume copy of the source subvolume.
 func (d *NASBlockStorageDriver) CreateSnapshot(
 	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
-) (*storage.Snapshot, error) {
+) (snapshot *storage.Snapshot, err error) {
 	snapName := snapConfig.Name
 	internalVolName := snapConfig.VolumeInternalName
 
@@ -1328,14 +2982,28 @@ func (d *NASBlockStorageDriver) CreateSnapshot(
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateSnapshot")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateSnapshot")
 
+	startTime := time.Now()
+	defer func() { recordSubvolumeOperation(d.BackendName(), "create_snapshot", startTime, err) }()
+
 	// Validate snapshot name
 	if err := d.validateSnapshotName(snapName); err != nil {
 		return nil, err
 	}
 
+	// A snapshot is itself a subvolume, but snapshotting one would produce a confusingly named snapshot-of-a-snapshot
+	if d.helper.IsValidSnapshotInternalName(volConfig.InternalName) {
+		return nil, errors.UnsupportedError(fmt.Sprintf(
+			"subvolume %s is a snapshot and may not itself be snapshotted", volConfig.InternalName))
+	}
+
 	// Create the snapshot name/string
 	creationToken := d.helper.GetSnapshotInternalName(snapConfig.VolumeName, snapName)
 
+	// Explain an overlong creation token in terms of its parts, before the generic creation token check below
+	if err := d.validateSnapshotInternalNameLength(creationToken, snapConfig.VolumeName); err != nil {
+		return nil, err
+	}
+
 	// Make sure we got a valid creation token
 	if err := d.validateCreationToken(creationToken); err != nil {
 		return nil, err
@@ -1357,6 +3025,16 @@ func (d *NASBlockStorageDriver) CreateSnapshot(
 		return nil, fmt.Errorf("error checking for existing snapshot %s; %v", creationToken, err)
 	}
 
+	// An existing snapshot is normally reused, but may be rejected for callers that expect a fresh one.  A snapshot
+	// that is still being created is only being retried while its create finishes, so allow that.
+	if snapshotExists && d.Config.RejectExistingSnapshots {
+		switch subvolume.ProvisioningState {
+		case api.StateAccepted, api.StateCreating:
+		default:
+			return nil, errors.FoundError("snapshot %s already exists", creationToken)
+		}
+	}
+
 	if !snapshotExists {
 		// NOTE: Do not get the source subvolume, that later causes get metadata to fail.
 
@@ -1376,16 +3054,25 @@ func (d *NASBlockStorageDriver) CreateSnapshot(
 			Parent:        sourceSubvolumeName, // Needed only when cloning
 		}
 
+		if d.Config.DryRun {
+			return nil, d.dryRunCreate(ctx, "CreateSnapshot", subvolumeCreateRequest)
+		}
+
+		if err = d.volumeRateLimiters.Wait(ctx, filePoolVolume); err != nil {
+			return nil, err
+		}
+
 		// Create the snapshot
 		subvolume, poller, err = d.SDK.CreateSubvolume(ctx, subvolumeCreateRequest)
 		if err != nil {
 			return nil, err
 		}
-	}
 
-	// Reading the creation timestamp from the subvolume metadata is expensive, instead
-	// use the current timestamp
-	createdAt := time.Now()
+		// The snapshot's ID is known, so use it if Azure has not assigned one yet
+		if subvolume.ID == "" {
+			subvolume.ID = snapshotInternalID
+		}
+	}
 
 	// Save the Poller's reference for later uses (if needed)
 	pollerKey := PollerKey{
@@ -1393,15 +3080,40 @@ func (d *NASBlockStorageDriver) CreateSnapshot(
 		Operation: Create,
 	}
 
-	pollerResponseCache[pollerKey] = poller
+	d.pollers.Set(pollerKey, poller)
 
-	if err = d.waitForSubvolumeCreate(ctx, subvolume, poller, pollerKey.Operation, false); err != nil {
+	if err = d.waitForSubvolumeCreate(ctx, subvolume, poller, pollerKey.Operation, false,
+		d.snapshotTimeout); err != nil {
 		return nil, err
 	}
 
+	// Reading the creation timestamp from the subvolume metadata is expensive, so use the current
+	// timestamp for a new snapshot, but report the real creation time if the snapshot already existed
+	createdAt := time.Now()
+	if snapshotExists {
+		if snapshotWithMetadata, metadataErr := d.SDK.SubvolumeByID(ctx, snapshotInternalID,
+			true); metadataErr != nil {
+			Logc(ctx).WithField("snapshot", creationToken).WithError(metadataErr).Warning(
+				"Could not read snapshot metadata; using current time as creation time.")
+		} else {
+			createdAt = d.checkSnapshotCreationTime(ctx, creationToken, snapshotWithMetadata.Created)
+		}
+	}
+
 	// For this driver the internal name and the name are different so set the internal name
 	snapConfig.InternalName = creationToken
 
+	// A snapshot is a full clone of its source, so it has the same size as the source subvolume
+	sizeBytes := subvolume.Size
+	if sizeBytes == 0 && volConfig.Size != "" {
+		if sourceSizeBytes, parseErr := strconv.ParseInt(volConfig.Size, 10, 64); parseErr != nil {
+			Logc(ctx).WithField("size", volConfig.Size).WithError(parseErr).Warning(
+				"Could not determine snapshot size.")
+		} else {
+			sizeBytes = sourceSizeBytes
+		}
+	}
+
 	Logc(ctx).WithFields(LogFields{
 		"snapshotName": snapConfig.InternalName,
 		"volumeName":   snapConfig.VolumeInternalName,
@@ -1410,17 +3122,100 @@ func (d *NASBlockStorageDriver) CreateSnapshot(
 	return &storage.Snapshot{
 		Config:    snapConfig,
 		Created:   createdAt.UTC().Format(utils.TimestampFormat),
-		SizeBytes: 0,
+		SizeBytes: sizeBytes,
 		State:     storage.SnapshotStateOnline,
 	}, nil
 }
 
+// checkSnapshotCreationTime compares a backend-reported snapshot creation time to the local clock.  A snapshot
+// cannot have been created in the future, so a creation time further ahead than the clock skew threshold indicates
+// clock skew or a stale read.  That is logged and, if the backend is so configured, the local time is used instead.
+func (d *NASBlockStorageDriver) checkSnapshotCreationTime(
+	ctx context.Context, snapshotName string, created time.Time,
+) time.Time {
+	now := time.Now()
+	skew := created.Sub(now)
+	if skew <= d.clockSkewThreshold {
+		return created
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"snapshot":     snapshotName,
+		"created":      created.UTC().Format(utils.TimestampFormat),
+		"localTime":    now.UTC().Format(utils.TimestampFormat),
+		"skew":         skew,
+		"useLocalTime": d.Config.UseLocalTimeOnClockSkew,
+	}).Warning("Snapshot creation time is ahead of the local clock; possible clock skew.")
+
+	if d.Config.UseLocalTimeOnClockSkew {
+		return now
+	}
+	return created
+}
+
+// restoreStep identifies the next step of an in-place restore once its temporary `-og` subvolume exists.
+type restoreStep int
+
+const (
+	// restoreStepDeleteOriginal means the actual subvolume has not been touched yet
+	restoreStepDeleteOriginal restoreStep = iota
+	// restoreStepAwaitOriginalDelete means the actual subvolume is still being deleted
+	restoreStepAwaitOriginalDelete
+	// restoreStepCreateRestored means the actual subvolume is gone and must be recreated from the snapshot
+	restoreStepCreateRestored
+	// restoreStepAwaitRestored means the subvolume has already been recreated from the snapshot
+	restoreStepAwaitRestored
+)
+
+// getRestoreStep works out from the backend alone how far an interrupted restore of a subvolume got, so that the
+// restore can resume even after a restart has discarded the saved pollers.  It must only be called once the
+// temporary subvolume is known to exist, since only then is a missing subvolume evidence of an earlier restore.
+// A subvolume created after the temporary subvolume was recreated by that restore, so if it was recreated from
+// a different snapshot, an InProgressError is returned until that restore finishes.
+func (d *NASBlockStorageDriver) getRestoreStep(
+	ctx context.Context, tempSubvolume *api.Subvolume, subvolumeID, snapshotName string,
+) (restoreStep, error) {
+	subvolume, err := d.SDK.SubvolumeByID(ctx, subvolumeID, true)
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			return restoreStepCreateRestored, nil
+		}
+		return restoreStepDeleteOriginal, err
+	}
+
+	if subvolume.ProvisioningState == api.StateDeleting {
+		return restoreStepAwaitOriginalDelete, nil
+	}
+
+	// An unknown parent path is not proof of a restore, so only a known one is checked against the snapshot
+	if subvolume.ParentPath == "" || subvolume.Created.IsZero() {
+		return restoreStepDeleteOriginal, nil
+	}
+
+	tempSubvolumeWithMetadata, err := d.SDK.SubvolumeByID(ctx, tempSubvolume.ID, true)
+	if err != nil {
+		return restoreStepDeleteOriginal, err
+	}
+	if tempSubvolumeWithMetadata.Created.IsZero() || !subvolume.Created.After(tempSubvolumeWithMetadata.Created) {
+		// The subvolume predates the temporary subvolume, so it has not been touched by this restore
+		return restoreStepDeleteOriginal, nil
+	}
+
+	if !isSubvolumeParentPath(subvolume.ParentPath, snapshotName) {
+		return restoreStepDeleteOriginal, errors.InProgressError(fmt.Sprintf(
+			"restore of subvolume %s from snapshot %s is already in progress", subvolume.Name,
+			strings.TrimPrefix(subvolume.ParentPath, "/")))
+	}
+
+	return restoreStepAwaitRestored, nil
+}
+
 // RestoreSnapshot restores a volume (in place) from a snapshot.
 // Subvolume driver does not support in-place restore or renaming of subvolumes, so the "snapshot restore"
 // operation works by deleting the original subvolume and replacing it with a clone of the snapshot copy.
 func (d *NASBlockStorageDriver) RestoreSnapshot(
 	ctx context.Context, snapConfig *storage.SnapshotConfig, volConfig *storage.VolumeConfig,
-) error {
+) (err error) {
 	internalSnapName := snapConfig.InternalName
 	internalVolName := volConfig.InternalName
 	internalVolID := volConfig.InternalID
@@ -1440,10 +3235,17 @@ func (d *NASBlockStorageDriver) RestoreSnapshot(
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> RestoreSnapshot")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< RestoreSnapshot")
 
+	startTime := time.Now()
+	defer func() { recordSubvolumeOperation(d.BackendName(), "restore_snapshot", startTime, err) }()
+
 	if volConfig.InternalName != snapConfig.VolumeInternalName {
 		return fmt.Errorf("snapshot/volume mismatch")
 	}
 
+	if d.Config.DryRun {
+		return d.dryRunModify(ctx, "RestoreSnapshot", internalVolName)
+	}
+
 	_, resourceGroup, _, netappAccount, cPoolName, volumeName, _, err := api.ParseSubvolumeID(volConfig.InternalID)
 	if err != nil {
 		Logc(ctx).WithError(err).Errorf("error parsing source volume config internal ID '%s'",
@@ -1466,25 +3268,38 @@ func (d *NASBlockStorageDriver) RestoreSnapshot(
 		return nil
 	}
 
+	// Check to see if `-og` subvolume already exists
+	tempSubvolumeExists, tempSubvolume, err := d.SDK.SubvolumeExistsByID(ctx, tempInternalVolID)
+	if err != nil {
+		Logc(ctx).WithError(err).Errorf("Error checking for existing subvolume: %v", err)
+		return errors.InProgressError(err.Error())
+	}
+
+	// A temporary subvolume left behind means an earlier restore was interrupted, so pick up where it left off.
+	// Restoring deletes and recreates the subvolume, so a restore from another snapshot must wait for it.
+	step := restoreStepDeleteOriginal
+	if tempSubvolumeExists {
+		if step, err = d.getRestoreStep(ctx, tempSubvolume, internalVolID, internalSnapName); err != nil {
+			if !errors.IsInProgressError(err) {
+				Logc(ctx).WithError(err).Errorf("error checking progress of restoring subvolume '%s'",
+					internalVolName)
+			}
+			return errors.InProgressError(err.Error())
+		}
+	}
+
 	// Check if subvolume restore already in progress
 	pollerKey := PollerKey{
 		ID:        internalVolName,
 		Operation: Restore,
 	}
 
-	poller, ok := pollerResponseCache[pollerKey]
+	poller, ok := d.pollers.Get(pollerKey)
 
 	if !ok {
 		// Create name of the volume where this `-og` subvolume will live
 		filePoolVolume := api.CreateVolumeFullName(resourceGroup, netappAccount, cPoolName, volumeName)
 
-		// Check to see if `-og` subvolume already exists
-		tempSubvolumeExists, tempSubvolume, err := d.SDK.SubvolumeExistsByID(ctx, tempInternalVolID)
-		if err != nil {
-			Logc(ctx).WithError(err).Errorf("Error checking for existing subvolume: %v", err)
-			return errors.InProgressError(err.Error())
-		}
-
 		if !tempSubvolumeExists {
 			Logc(ctx).WithFields(LogFields{
 				"creationToken": tempInternalVolName,
@@ -1508,15 +3323,28 @@ func (d *NASBlockStorageDriver) RestoreSnapshot(
 			}
 		}
 
-		// Save the Poller's reference for later uses (if needed)
+		// Every poller saved during a restore is keyed by the Restore operation, so it never collides with one
+		// saved by Create, and by the name waitForSubvolumeCreate uses to remove it
 		pollerKey = PollerKey{
 			ID:        tempSubvolume.Name,
-			Operation: Create,
+			Operation: Restore,
 		}
 
-		pollerResponseCache[pollerKey] = poller
+		if tempSubvolumeExists {
+			Logc(ctx).WithFields(LogFields{
+				"subvolume": internalVolName,
+				"step":      step,
+			}).Debug("Resuming interrupted subvolume restore.")
+
+			// Resume waiting on the poller saved when an earlier attempt created the temporary subvolume, if any
+			poller, _ = d.pollers.Get(pollerKey)
+		} else {
+			// Save the Poller's reference for later uses (if needed)
+			d.pollers.Set(pollerKey, poller)
+		}
 
-		if err = d.waitForSubvolumeCreate(ctx, tempSubvolume, poller, pollerKey.Operation, false); err != nil {
+		if err = d.waitForSubvolumeCreate(ctx, tempSubvolume, poller, pollerKey.Operation, false,
+			d.volumeCreateTimeout); err != nil {
 			if errors.IsVolumeCreatingError(err) {
 				return errors.InProgressError(err.Error())
 			}
@@ -1536,40 +3364,57 @@ func (d *NASBlockStorageDriver) RestoreSnapshot(
 			Name:          internalVolName,
 		}
 
-		if err = d.deleteSubvolume(subvolume); err != nil {
+		// If an earlier attempt timed out while the actual subvolume was still deleting, its provisioning state
+		// says so, and that delete is waited on rather than requesting another one
+		switch step {
+		case restoreStepDeleteOriginal:
+			err = d.deleteSubvolume(ctx, subvolume, d.deleteTimeout)
+		case restoreStepAwaitOriginalDelete:
+			err = d.waitForSubvolumeDelete(ctx, subvolume, nil, d.deleteTimeout)
+		}
+		if err != nil {
 			Logc(ctx).WithError(err).Errorf("failed to delete the actual subvolume '%s'", internalVolName)
 			return errors.InProgressError(err.Error())
 		}
 
-		// Create the subvolume again using snapshot
-		Logc(ctx).WithFields(LogFields{
-			"creationToken": internalVolName,
-			"volume":        filePoolVolume,
-			"parentPath":    internalSnapName,
-		}).Debug("Creating subvolume from snapshot.")
+		if step == restoreStepAwaitRestored {
+			// An earlier attempt already recreated the subvolume from the snapshot, so only wait on it below
+			pollerKey = PollerKey{
+				ID:        internalVolName,
+				Operation: Restore,
+			}
+			poller = nil
+		} else {
+			// Create the subvolume again using snapshot
+			Logc(ctx).WithFields(LogFields{
+				"creationToken": internalVolName,
+				"volume":        filePoolVolume,
+				"parentPath":    internalSnapName,
+			}).Debug("Creating subvolume from snapshot.")
 
-		// Create a subvolume request using snapshot
-		subvolumeCreateRequest := &api.SubvolumeCreateRequest{
-			CreationToken: internalVolName,
-			Volume:        filePoolVolume,
-			Parent:        internalSnapName, // Needed only when cloning
-		}
+			// Create a subvolume request using snapshot
+			subvolumeCreateRequest := &api.SubvolumeCreateRequest{
+				CreationToken: internalVolName,
+				Volume:        filePoolVolume,
+				Parent:        internalSnapName, // Needed only when cloning
+			}
 
-		// Create the subvolume using snapshot
-		subvolume, poller, err = d.SDK.CreateSubvolume(ctx, subvolumeCreateRequest)
-		if err != nil {
-			Logc(ctx).WithError(err).Errorf("error creating subvolume '%s' from snapshot '%s'",
-				internalVolName, internalSnapName)
-			return errors.InProgressError(err.Error())
-		}
+			// Create the subvolume using snapshot
+			subvolume, poller, err = d.SDK.CreateSubvolume(ctx, subvolumeCreateRequest)
+			if err != nil {
+				Logc(ctx).WithError(err).Errorf("error creating subvolume '%s' from snapshot '%s'",
+					internalVolName, internalSnapName)
+				return errors.InProgressError(err.Error())
+			}
 
-		// Save the Poller's reference for later uses (if needed)
-		pollerKey = PollerKey{
-			ID:        subvolume.Name,
-			Operation: Restore,
-		}
+			// Save the Poller's reference for later uses (if needed)
+			pollerKey = PollerKey{
+				ID:        subvolume.Name,
+				Operation: Restore,
+			}
 
-		pollerResponseCache[pollerKey] = poller
+			d.pollers.Set(pollerKey, poller)
+		}
 	}
 
 	// Create Subvolume Object
@@ -1582,7 +3427,8 @@ func (d *NASBlockStorageDriver) RestoreSnapshot(
 		Name:          internalVolName,
 	}
 
-	if err = d.waitForSubvolumeCreate(ctx, subvolume, poller, pollerKey.Operation, false); err != nil {
+	if err = d.waitForSubvolumeCreate(ctx, subvolume, poller, pollerKey.Operation, false,
+		d.volumeCreateTimeout); err != nil {
 		if errors.IsVolumeCreatingError(err) {
 			return errors.InProgressError(err.Error())
 		}
@@ -1603,19 +3449,15 @@ func (d *NASBlockStorageDriver) RestoreSnapshot(
 	}
 
 	// If temporary subvolume delete fails, then throwing an error would cause the complete
-	// restore process to repeat; thus adding a retry here to give best shot at deleting
-	// the temporary subvolume.
-	if err = d.deleteSubvolume(subvolume); err != nil {
-		Logc(ctx).WithError(err).Errorf("failed to delete the temporary subvolume '%s'; retrying", tempInternalVolName)
-
-		if err = d.deleteSubvolume(subvolume); err != nil {
-			Logc(ctx).WithError(err).Errorf("failed to delete the temporary subvolume '%s'", tempInternalVolName)
+	// restore process to repeat; deleteSubvolume retries transient failures, and anything
+	// left over is handed to the fail-safe below.
+	if err = d.deleteSubvolume(ctx, subvolume, d.deleteTimeout); err != nil {
+		Logc(ctx).WithError(err).Errorf("failed to delete the temporary subvolume '%s'", tempInternalVolName)
 
-			// Fail-safe mechanism to ensure temporary subvolume is definitely deleted.
-			d.ensureSubvolumeDelete(tempInternalVolID, snapshotInternalID)
+		// Fail-safe mechanism to ensure temporary subvolume is definitely deleted.
+		d.ensureSubvolumeDelete(tempInternalVolID, snapshotInternalID)
 
-			return errors.InProgressError(err.Error())
-		}
+		return errors.InProgressError(err.Error())
 	}
 
 	return nil
@@ -1657,7 +3499,11 @@ func (d *NASBlockStorageDriver) DeleteSnapshot(
 		Name:          creationToken,
 	}
 
-	return d.deleteSubvolume(subvolume)
+	if d.Config.DryRun {
+		return d.dryRunDelete(ctx, "DeleteSnapshot", subvolume)
+	}
+
+	return d.deleteSubvolume(ctx, subvolume, d.snapshotTimeout)
 }
 
 // Get tests for the existence of a volume
@@ -1673,19 +3519,50 @@ func (d *NASBlockStorageDriver) Get(ctx context.Context, name string) error {
 	return nil
 }
 
-// Resize increases a volume's quota.
-func (d *NASBlockStorageDriver) Resize(ctx context.Context, volConfig *storage.VolumeConfig, sizeBytes uint64) error {
-	name := volConfig.InternalName
+// GetVolume returns the subvolume with the specified name (creation token), including the size and state read
+// from its metadata, as reported by the SDK.  A NotFoundError is returned if the subvolume does not exist.
+func (d *NASBlockStorageDriver) GetVolume(ctx context.Context, name string) (*api.Subvolume, error) {
+	fields := LogFields{"Method": "GetVolume", "Type": "NASBlockStorageDriver", "name": name}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> GetVolume")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< GetVolume")
 
-	fields := LogFields{
-		"Method":    "Resize",
-		"Type":      "NASBlockStorageDriver",
-		"name":      name,
-		"sizeBytes": sizeBytes,
+	subvolume, err := d.SDK.SubvolumeByCreationToken(ctx, name, d.getAllFilePoolVolumes(), true)
+	if err != nil {
+		if errors.IsNotFoundError(err) {
+			return nil, errors.WrapWithNotFoundError(err, "subvolume %s not found", name)
+		}
+		return nil, fmt.Errorf("could not get subvolume %s; %v", name, err)
+	}
+	if subvolume == nil {
+		return nil, errors.NotFoundError("subvolume %s not found", name)
 	}
+
+	return subvolume, nil
+}
+
+// Resize increases a volume's quota.
+func (d *NASBlockStorageDriver) Resize(
+	ctx context.Context, volConfig *storage.VolumeConfig, sizeBytes uint64,
+) error {
+	fields := addSizeLogFields(LogFields{
+		"Method": "Resize",
+		"Type":   "NASBlockStorageDriver",
+		"name":   volConfig.InternalName,
+	}, "size", sizeBytes)
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> Resize")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< Resize")
 
+	startTime := time.Now()
+	err := d.resize(ctx, volConfig, sizeBytes)
+	recordSubvolumeOperation(d.BackendName(), "resize", startTime, err)
+
+	return err
+}
+
+// resize does the work of Resize.
+func (d *NASBlockStorageDriver) resize(ctx context.Context, volConfig *storage.VolumeConfig, sizeBytes uint64) error {
+	name := volConfig.InternalName
+
 	// Get the subvolume
 	subvolumeWithMetadata, err := d.SDK.Subvolume(ctx, volConfig, true)
 	if err != nil {
@@ -1714,15 +3591,83 @@ func (d *NASBlockStorageDriver) Resize(ctx context.Context, volConfig *storage.V
 		return err
 	}
 
-	// Resize the subvolume
-	if err = d.SDK.ResizeSubvolume(ctx, subvolumeWithMetadata, int64(sizeBytes)); err != nil {
+	if err = d.checkMaxVolumeSize(sizeBytes); err != nil {
 		return err
 	}
 
+	if d.Config.DryRun {
+		return d.dryRunModify(ctx, "Resize", name)
+	}
+
+	resizeCtx, cancel := context.WithTimeout(ctx, d.resizeTimeout)
+	defer cancel()
+
+	// Resize the subvolume, growing the parent volume first if it lacks room and the backend allows it
+	if err = d.SDK.ResizeSubvolume(resizeCtx, subvolumeWithMetadata, int64(sizeBytes)); err != nil {
+		if !api.IsANFInsufficientSpaceError(err) {
+			return err
+		}
+		if err = d.growParentVolumeForResize(resizeCtx, volConfig, subvolumeWithMetadata, sizeBytes,
+			err); err != nil {
+			return err
+		}
+		if err = d.SDK.ResizeSubvolume(resizeCtx, subvolumeWithMetadata, int64(sizeBytes)); err != nil {
+			return err
+		}
+	}
+
 	volConfig.Size = strconv.FormatUint(sizeBytes, 10)
 	return nil
 }
 
+// growParentVolumeForResize grows a subvolume's parent file pool volume by the space a subvolume resize
+// needs but the parent volume lacks.  If autoGrowParentVolume is not set, it returns a descriptive error
+// naming the parent volume and the shortfall instead.
+func (d *NASBlockStorageDriver) growParentVolumeForResize(
+	ctx context.Context, volConfig *storage.VolumeConfig, subvolume *api.Subvolume, sizeBytes uint64,
+	resizeErr error,
+) error {
+	volume, err := d.SDK.SubvolumeParentVolume(ctx, volConfig)
+	if err != nil {
+		return fmt.Errorf("could not find subvolume's ('%s') parent volume: %v; %v",
+			volConfig.InternalName, err, resizeErr)
+	}
+
+	// Compute the shortfall from the parent volume's free space.  If the parent volume appears to have
+	// room, ANF disagrees with our view of its usage, so request the full growth of the subvolume.
+	growthBytes := int64(sizeBytes) - subvolume.Size
+	shortfallBytes := growthBytes - (volume.QuotaInBytes - int64(volume.UsedBytes))
+	if shortfallBytes <= 0 {
+		shortfallBytes = growthBytes
+	}
+
+	if !d.Config.AutoGrowParentVolume {
+		return fmt.Errorf("parent volume %s lacks %d bytes needed to resize subvolume %s to %d bytes; "+
+			"grow the parent volume or enable autoGrowParentVolume; %v", volume.FullName, shortfallBytes,
+			volConfig.InternalName, sizeBytes, resizeErr)
+	}
+
+	// ANF volume quotas are allocated in whole GiB
+	newQuotaBytes := volume.QuotaInBytes + shortfallBytes
+	if remainder := newQuotaBytes % parentVolumeSizeIncrementBytes; remainder != 0 {
+		newQuotaBytes += parentVolumeSizeIncrementBytes - remainder
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"volume":       volume.FullName,
+		"subvolume":    volConfig.InternalName,
+		"currentQuota": volume.QuotaInBytes,
+		"newQuota":     newQuotaBytes,
+		"shortfall":    shortfallBytes,
+	}).Info("Growing parent volume to make room for subvolume resize.")
+
+	if err = d.SDK.ResizeVolume(ctx, volume, newQuotaBytes); err != nil {
+		return fmt.Errorf("could not grow parent volume %s to %d bytes; %v", volume.FullName, newQuotaBytes, err)
+	}
+
+	return nil
+}
+
 // GetStorageBackendSpecs retrieves storage capabilities and register pools with specified backend.
 func (d *NASBlockStorageDriver) GetStorageBackendSpecs(_ context.Context, backend storage.Backend) error {
 	backend.SetName(d.BackendName())
@@ -1747,6 +3692,47 @@ func (d *NASBlockStorageDriver) GetStorageBackendSpecs(_ context.Context, backen
 // CreatePrepare is called prior to volume creation. Currently its only role is to create the internal volume name.
 func (d *NASBlockStorageDriver) CreatePrepare(ctx context.Context, volConfig *storage.VolumeConfig) {
 	volConfig.InternalName = d.GetInternalVolumeName(ctx, volConfig.Name)
+
+	if d.Config.DetectNameCollisions {
+		if filePoolVolume, found := d.findCreationTokenCollision(ctx, volConfig.InternalName); found {
+			Logc(ctx).WithFields(LogFields{
+				"creationToken":  volConfig.InternalName,
+				"filePoolVolume": filePoolVolume,
+			}).Warning("A subvolume with this creation token already exists; unless it was created by an earlier " +
+				"attempt to create this volume, lookups of the new subvolume by name may be ambiguous.")
+		}
+	}
+}
+
+// findCreationTokenCollision looks for an existing subvolume with the specified creation token in any of the
+// backend's file pool volumes, and returns the first file pool volume that has one.  Errors reading a file pool
+// volume are logged and that volume is skipped, as this check is advisory.
+func (d *NASBlockStorageDriver) findCreationTokenCollision(
+	ctx context.Context, creationToken string,
+) (string, bool) {
+	for _, filePoolVolume := range d.getAllFilePoolVolumes() {
+		resourceGroup, netappAccount, cPoolName, volumeName, err := api.ParseVolumeName(filePoolVolume)
+		if err != nil {
+			Logc(ctx).WithField("filePoolVolume", filePoolVolume).WithError(err).Debug(
+				"Could not parse file pool volume name.")
+			continue
+		}
+
+		subvolumeID := api.CreateSubvolumeID(d.Config.SubscriptionID, resourceGroup, netappAccount, cPoolName,
+			volumeName, creationToken)
+
+		exists, _, err := d.SDK.SubvolumeExistsByID(ctx, subvolumeID)
+		if err != nil {
+			Logc(ctx).WithField("filePoolVolume", filePoolVolume).WithError(err).Debug(
+				"Could not check file pool volume for a colliding subvolume.")
+			continue
+		}
+		if exists {
+			return filePoolVolume, true
+		}
+	}
+
+	return "", false
 }
 
 // GetStorageBackendPhysicalPoolNames retrieves storage backend physical pools
@@ -1783,23 +3769,90 @@ func (d *NASBlockStorageDriver) getStorageBackendPools(ctx context.Context) []dr
 	return backendPools
 }
 
-// GetInternalVolumeName accepts the name of a volume being created and returns what the internal name
-// should be, depending on backend requirements and Trident's operating context.
-func (d *NASBlockStorageDriver) GetInternalVolumeName(ctx context.Context, name string) string {
-	if tridentconfig.UsingPassthroughStore {
-		// With a passthrough store, the name mapping must remain reversible
-		return *d.Config.StoragePrefix + name
-	} else {
-		// With an external store, any transformation of the name is fine
-		internal := drivers.GetCommonInternalVolumeName(d.Config.CommonStorageDriverConfig, name)
-		internal = internal + api.SubvolumeNameSeparator + "0"
-		Logc(ctx).WithField("volumeInternal", internal).Debug("Modified volume name for internal name.")
-		return internal
-	}
+// nameTemplateData holds the values available to a nameTemplate: the volume name, the storage prefix, and the
+// suffix the default scheme appends to every subvolume name.
+type nameTemplateData struct {
+	Name   string
+	Prefix string
+	Suffix string
 }
 
-func (d *NASBlockStorageDriver) CreateFollowup(ctx context.Context, volConfig *storage.VolumeConfig) error {
-	creationToken := volConfig.InternalName
+// parseNameTemplate parses a nameTemplate and checks that, for the longest volume name Trident generates, it yields
+// a valid creation token that includes the volume name, begins with the storage prefix and its separator, and does
+// not contain the snapshot name separator.  Nil is returned if no template is set.
+func parseNameTemplate(nameTemplate, storagePrefix string) (*template.Template, error) {
+	if nameTemplate == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New("nameTemplate").Option("missingkey=error").Parse(nameTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	// PVC names are "pvc-" followed by a UUID
+	sampleName := "pvc-" + strings.Repeat("0", maxSubvolumeNameLength-len("pvc-"))
+	sample, err := executeNameTemplate(tmpl, sampleName, storagePrefix)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(sample, sampleName) {
+		return nil, errors.New("template must include {{.Name}} so that each volume has a unique name")
+	}
+	if !subvolumeCreationTokenRegex.MatchString(sample) {
+		return nil, fmt.Errorf("template yields names like '%s', which are not valid subvolume names; they must "+
+			"be at most %d characters long, begin with a letter, and contain only letters, digits, and hyphens",
+			sample, maxCreationTokenLength)
+	}
+
+	// Trident recognizes its subvolumes by the storage prefix, and snapshot names are split on '--'
+	if prefix := storagePrefixWithSeparator(storagePrefix); !strings.HasPrefix(sample, prefix) {
+		return nil, fmt.Errorf("template yields names like '%s', which do not begin with '%s'; Trident would "+
+			"not recognize them as its own subvolumes", sample, prefix)
+	}
+	if strings.Contains(sample, snapshotNameSeparator) {
+		return nil, fmt.Errorf("template yields names like '%s', which contain '%s'", sample, snapshotNameSeparator)
+	}
+
+	return tmpl, nil
+}
+
+// executeNameTemplate applies a nameTemplate to a volume name.
+func executeNameTemplate(tmpl *template.Template, name, storagePrefix string) (string, error) {
+	var internal strings.Builder
+	data := nameTemplateData{Name: name, Prefix: storagePrefix, Suffix: api.SubvolumeNameSeparator + "0"}
+	if err := tmpl.Execute(&internal, data); err != nil {
+		return "", err
+	}
+	return internal.String(), nil
+}
+
+// GetInternalVolumeName accepts the name of a volume being created and returns what the internal name
+// should be, depending on backend requirements and Trident's operating context.
+func (d *NASBlockStorageDriver) GetInternalVolumeName(ctx context.Context, name string) string {
+	if tridentconfig.UsingPassthroughStore {
+		// With a passthrough store, the name mapping must remain reversible
+		return *d.Config.StoragePrefix + name
+	} else {
+		// With an external store, any transformation of the name is fine
+		if d.nameTemplate != nil {
+			internal, err := executeNameTemplate(d.nameTemplate, name, *d.Config.StoragePrefix)
+			if err == nil {
+				Logc(ctx).WithField("volumeInternal", internal).Debug("Applied name template for internal name.")
+				return internal
+			}
+			Logc(ctx).WithField("name", name).WithError(err).Warning(
+				"Could not apply name template; using the default internal name.")
+		}
+		internal := drivers.GetCommonInternalVolumeName(d.Config.CommonStorageDriverConfig, name)
+		internal = internal + api.SubvolumeNameSeparator + "0"
+		Logc(ctx).WithField("volumeInternal", internal).Debug("Modified volume name for internal name.")
+		return internal
+	}
+}
+
+func (d *NASBlockStorageDriver) CreateFollowup(ctx context.Context, volConfig *storage.VolumeConfig) error {
+	creationToken := volConfig.InternalName
 
 	fields := LogFields{
 		"Method": "CreateFollowup",
@@ -1810,14 +3863,29 @@ func (d *NASBlockStorageDriver) CreateFollowup(ctx context.Context, volConfig *s
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CreateFollowup")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CreateFollowup")
 
-	subvolume, err := d.SDK.Subvolume(ctx, volConfig, false)
+	var subvolume *api.Subvolume
+	var err error
+
+	if err = validateFileSystemType(volConfig.FileSystem); err != nil {
+		return err
+	}
+
+	// The subvolume's ID may not have been known when it was created, so record it now
+	if volConfig.InternalID == "" {
+		subvolume, err = d.SDK.SubvolumeByCreationToken(ctx, creationToken, d.getAllFilePoolVolumes(), false)
+		if err == nil {
+			volConfig.InternalID = subvolume.ID
+		}
+	} else {
+		subvolume, err = d.SDK.Subvolume(ctx, volConfig, false)
+	}
 	if err != nil {
 		return fmt.Errorf("could not find subvolume %s; %v", creationToken, err)
 	}
 
-	volume, err := d.SDK.SubvolumeParentVolume(ctx, volConfig)
+	volume, err := d.getSubvolumeParentVolume(ctx, volConfig)
 	if err != nil {
-		return fmt.Errorf("could not find subvolume's ('%s') parent volume: %v", creationToken, err)
+		return err
 	}
 
 	// Ensure subvolume is in a good state
@@ -1826,10 +3894,17 @@ func (d *NASBlockStorageDriver) CreateFollowup(ctx context.Context, volConfig *s
 	}
 
 	// Set the correct NFS mount option based on volume's protocol
-	NFSMountOption := fmt.Sprintf("vers=%s", strings.TrimPrefix(volume.ProtocolTypes[0],
-		api.ProtocolTypeNFSPrefix))
+	NFSMountOption, err := d.getNFSVersionMountOption(volume)
+	if err != nil {
+		return err
+	}
 	mountOptions := utils.SetNFSVersionMountOptions(d.Config.NfsMountOptions, NFSMountOption)
 
+	// Ensure the parent volume supports the requested security flavors
+	if err = validateSecurityFlavors(volume, getSecurityFlavors(mountOptions)); err != nil {
+		return err
+	}
+
 	if len(volume.MountTargets) == 0 {
 		return fmt.Errorf("volume %s has no mount targets", volume.Name)
 	}
@@ -1839,7 +3914,8 @@ func (d *NASBlockStorageDriver) CreateFollowup(ctx context.Context, volConfig *s
 	volConfig.AccessInfo.NfsPath = "/" + volume.CreationToken
 	volConfig.AccessInfo.NfsUniqueID = d.createFilePoolVolumePathHash(volume)
 	volConfig.AccessInfo.SubvolumeName = volConfig.InternalName
-	volConfig.AccessInfo.MountOptions = strings.TrimPrefix(mountOptions, "-o ")
+	volConfig.AccessInfo.MountOptions = trimMountOptionsFlag(mountOptions)
+	volConfig.AccessInfo.SubvolumeUnixPermissions = volConfig.UnixPermissions
 
 	if !strings.Contains(volConfig.FileSystem, "nfs/") {
 		volConfig.FileSystem = fmt.Sprintf("nfs/%s", volConfig.FileSystem)
@@ -1848,15 +3924,43 @@ func (d *NASBlockStorageDriver) CreateFollowup(ctx context.Context, volConfig *s
 	return nil
 }
 
+// GetCapabilities returns the operations this backend supports.  Subvolumes may be cloned, snapshotted, grown,
+// and imported, but never shrunk, since shrinking the file would truncate the filesystem inside it.
+func (d *NASBlockStorageDriver) GetCapabilities() drivers.BackendCapabilities {
+	return drivers.BackendCapabilities{
+		Clones:    true,
+		Snapshots: true,
+		Resize:    true,
+		Shrink:    false,
+		Import:    true,
+	}
+}
+
 // GetProtocol returns the protocol supported by this driver (BlockOnFile).
 func (d *NASBlockStorageDriver) GetProtocol(context.Context) tridentconfig.Protocol {
 	return tridentconfig.BlockOnFile
 }
 
 // StoreConfig adds this backend's config to the persistent config struct, as needed by Trident's persistence layer.
-func (d *NASBlockStorageDriver) StoreConfig(_ context.Context, b *storage.PersistentStorageBackendConfig) {
+// If the backend's credentials come from a user-provided secret, the stored config references that secret rather
+// than carrying the client ID and secret in cleartext.  Otherwise the persistence layer moves them into the
+// backend's own secret, so they are stored as-is here.
+func (d *NASBlockStorageDriver) StoreConfig(ctx context.Context, b *storage.PersistentStorageBackendConfig) {
 	drivers.SanitizeCommonStorageDriverConfig(d.Config.CommonStorageDriverConfig)
-	b.AzureConfig = &d.Config
+
+	// Clone the config so we don't risk altering the original
+	var storedConfig drivers.AzureNASStorageDriverConfig
+	drivers.Clone(ctx, d.Config, &storedConfig)
+
+	secretName, secretType, err := storedConfig.GetCredentials()
+	if err != nil {
+		Logc(ctx).WithError(err).Warning("Could not read credentials; redacting stored secrets.")
+		storedConfig.HideSensitiveWithSecretName(utils.REDACTED)
+	} else if secretName != "" {
+		storedConfig.HideSensitiveWithSecretName(fmt.Sprintf("%s:%s", secretType, secretName))
+	}
+
+	b.AzureConfig = &storedConfig
 }
 
 // GetExternalConfig returns a clone of this backend's config, sanitized for external consumption.
@@ -1869,6 +3973,9 @@ func (d *NASBlockStorageDriver) GetExternalConfig(ctx context.Context) interface
 		drivers.KeyName: utils.REDACTED,
 		drivers.KeyType: utils.REDACTED,
 	} // redact the credentials
+	if cloneConfig.ProxyURL != "" {
+		cloneConfig.ProxyURL = utils.REDACTED // the proxy URL may contain credentials
+	}
 	return cloneConfig
 }
 
@@ -1876,9 +3983,9 @@ func (d *NASBlockStorageDriver) GetExternalConfig(ctx context.Context) interface
 // a single container volume managed by this driver and returns a VolumeExternal
 // representation of the volume.
 func (d *NASBlockStorageDriver) GetVolumeExternal(ctx context.Context, name string) (*storage.VolumeExternal, error) {
-	subvolumeWithMetadata, err := d.SDK.SubvolumeByCreationToken(ctx, name, d.getAllFilePoolVolumes(), true)
+	subvolumeWithMetadata, err := d.GetVolume(ctx, name)
 	if err != nil {
-		return nil, fmt.Errorf("could not find subvolume %s: %v", name, err)
+		return nil, err
 	}
 
 	return d.getSubvolumeExternal(subvolumeWithMetadata), nil
@@ -1899,33 +4006,82 @@ func (d *NASBlockStorageDriver) GetVolumeExternalWrappers(
 	// Let the caller know we're done by closing the channel
 	defer close(channel)
 
-	prefix := *d.Config.StoragePrefix
-
-	subvolumes, err := d.SDK.Subvolumes(ctx, d.getAllFilePoolVolumes())
-	if err != nil {
-		channel <- &storage.VolumeExternalWrapper{Volume: nil, Error: err}
-		return
+	// List each file pool volume separately, so that one temporarily unavailable volume doesn't hide the
+	// subvolumes of the others
+	filePoolVolumes := d.getAllFilePoolVolumes()
+	listed := 0
+	for _, filePoolVolume := range filePoolVolumes {
+		err := d.streamVolumeExternalWrappers(ctx, filePoolVolume, strings.Join(filePoolVolumes, ", "), &listed,
+			channel)
+		if errors.IsMaxLimitReachedError(err) {
+			channel <- &storage.VolumeExternalWrapper{Volume: nil, Error: err}
+			return
+		} else if err != nil {
+			Logc(ctx).WithField("volume", filePoolVolume).WithError(err).Warning(
+				"Could not list subvolumes of file pool volume.")
+			channel <- &storage.VolumeExternalWrapper{
+				Volume: nil,
+				Error:  fmt.Errorf("could not list subvolumes of file pool volume %s; %v", filePoolVolume, err),
+			}
+		}
 	}
+}
 
-	for _, subvolume := range *subvolumes {
-
-		// Filter out subvolume in an unavailable state
-		switch subvolume.ProvisioningState {
-		case api.StateDeleting, api.StateDeleted, api.StateError:
-			continue
+// streamVolumeExternalWrappers lists the subvolumes of a file pool volume a page at a time, sending each one
+// managed by this backend to the channel, so that a file pool volume holding a very large number of subvolumes is
+// never read into memory at once.  Listed counts the subvolumes across all file pool volumes, and a
+// MaxLimitReachedError is returned, before the page is sent, once it exceeds maxSubvolumesListed.
+func (d *NASBlockStorageDriver) streamVolumeExternalWrappers(
+	ctx context.Context, filePoolVolume, allFilePoolVolumes string, listed *int,
+	channel chan *storage.VolumeExternalWrapper,
+) error {
+	return d.SDK.SubvolumePages(ctx, filePoolVolume, func(subvolumes []*api.Subvolume) error {
+		*listed += len(subvolumes)
+		if err := api.CheckSubvolumesListed(*listed, d.maxSubvolumesListed, allFilePoolVolumes); err != nil {
+			return err
 		}
 
-		// Filter out subvolume without the prefix (pass all if prefix is empty)
-		if !strings.HasPrefix(subvolume.Name, prefix) {
-			continue
-		}
+		for _, subvolume := range subvolumes {
 
-		if !d.isFileValidVolume(ctx, subvolume.Name) {
-			continue
+			// Filter out subvolume in an unavailable state
+			switch subvolume.ProvisioningState {
+			case api.StateDeleting, api.StateDeleted, api.StateError:
+				continue
+			}
+
+			// Filter out subvolume without the prefix (pass all if prefix is empty)
+			if !d.hasStoragePrefix(subvolume.Name) {
+				continue
+			}
+
+			if !d.isFileValidVolume(ctx, subvolume.Name) {
+				continue
+			}
+
+			channel <- &storage.VolumeExternalWrapper{Volume: d.getSubvolumeExternal(subvolume), Error: nil}
 		}
+		return nil
+	})
+}
+
+// hasStoragePrefix returns whether a subvolume name begins with this backend's storage prefix, followed by the
+// separator Trident places after the prefix, so that a prefix like "anf" does not match subvolumes of another
+// backend using a prefix like "anfprod".  All names match an empty prefix, so callers that would change or delete
+// subvolumes Trident might not own do nothing when the prefix is empty.
+func (d *NASBlockStorageDriver) hasStoragePrefix(subvolumeName string) bool {
+	prefix := *d.Config.StoragePrefix
+	if !strings.HasPrefix(subvolumeName, prefix) {
+		return false
+	}
 
-		channel <- &storage.VolumeExternalWrapper{Volume: d.getSubvolumeExternal(subvolume), Error: nil}
+	// A prefix that ends in a separator already marks its own boundary, and with a passthrough store the
+	// name is appended to the prefix without a separator
+	if prefix == "" || strings.HasSuffix(prefix, "-") || strings.HasSuffix(prefix, "_") ||
+		tridentconfig.UsingPassthroughStore {
+		return true
 	}
+
+	return strings.HasPrefix(subvolumeName[len(prefix):], "-")
 }
 
 func (d *NASBlockStorageDriver) isFileValidVolume(ctx context.Context, subvolumeName string) bool {
@@ -1946,8 +4102,8 @@ func (d *NASBlockStorageDriver) getSubvolumeExternal(subVolumeAttrs *api.Subvolu
 	name := internalName
 
 	// Remove Prefix
-	if strings.HasPrefix(internalName, *d.Config.StoragePrefix) {
-		name = internalName[len(*d.Config.StoragePrefix+"-"):]
+	if prefix := storagePrefixWithSeparator(*d.Config.StoragePrefix); strings.HasPrefix(internalName, prefix) {
+		name = internalName[len(prefix):]
 	}
 
 	// Remove Suffix
@@ -1957,6 +4113,7 @@ func (d *NASBlockStorageDriver) getSubvolumeExternal(subVolumeAttrs *api.Subvolu
 		Version:         tridentconfig.OrchestratorAPIVersion,
 		Name:            name,
 		InternalName:    internalName,
+		InternalID:      subVolumeAttrs.ID,
 		Size:            strconv.FormatInt(subVolumeAttrs.Size, 10),
 		Protocol:        tridentconfig.BlockOnFile,
 		SnapshotPolicy:  "",
@@ -1971,9 +4128,25 @@ func (d *NASBlockStorageDriver) getSubvolumeExternal(subVolumeAttrs *api.Subvolu
 		ServiceLevel:    "",
 	}
 
+	// A subvolume cloned from a snapshot names that snapshot as its parent
+	parent := strings.TrimPrefix(subVolumeAttrs.ParentPath, "/")
+	if parent != "" && d.helper.IsValidSnapshotInternalName(parent) {
+		volumeConfig.CloneSourceSnapshotInternal = parent
+		volumeConfig.CloneSourceSnapshot = d.helper.GetSnapshotNameFromSnapInternalName(parent)
+	}
+
+	// The provisioning state and parent volume help diagnose a subvolume stuck creating
+	parentVolume := ""
+	if subVolumeAttrs.Volume != "" {
+		parentVolume = api.CreateVolumeFullName(subVolumeAttrs.ResourceGroup, subVolumeAttrs.NetAppAccount,
+			subVolumeAttrs.CapacityPool, subVolumeAttrs.Volume)
+	}
+
 	return &storage.VolumeExternal{
-		Config: volumeConfig,
-		Pool:   subVolumeAttrs.Volume,
+		Config:       volumeConfig,
+		Pool:         subVolumeAttrs.Volume,
+		BackendState: subVolumeAttrs.ProvisioningState,
+		ParentVolume: parentVolume,
 	}
 }
 
@@ -2007,8 +4180,8 @@ func (d *NASBlockStorageDriver) GetUpdateType(_ context.Context, driverOrig stor
 	return bitmap
 }
 
-// ReconcileNodeAccess updates a per-backend export policy to match the set of Kubernetes cluster
-// nodes.  Not supported by this driver.
+// ReconcileNodeAccess updates the export policy of each parent file pool volume to match the set of
+// Kubernetes cluster nodes.
 func (d *NASBlockStorageDriver) ReconcileNodeAccess(ctx context.Context, nodes []*utils.Node, _, _ string) error {
 	nodeNames := make([]string, 0)
 	for _, node := range nodes {
@@ -2023,9 +4196,312 @@ func (d *NASBlockStorageDriver) ReconcileNodeAccess(ctx context.Context, nodes [
 	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> ReconcileNodeAccess")
 	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< ReconcileNodeAccess")
 
+	if !d.Config.AutoExportPolicy {
+		return nil
+	}
+
+	allowedClients, err := d.getDesiredAllowedClients(ctx, nodes)
+	if err != nil {
+		return fmt.Errorf("unable to determine desired export policy rules; %v", err)
+	}
+
+	// Rather than cutting off all access, leave the export policies alone until some node addresses are known
+	if allowedClients == "" {
+		Logc(ctx).Warning("No node addresses match the autoExportCIDRs; export policies not updated.")
+		return nil
+	}
+
+	var reconcileErrors []string
+
+	for _, filePoolVolume := range d.getAllFilePoolVolumes() {
+		if err = d.reconcileFilePoolVolumeAccess(ctx, filePoolVolume, allowedClients); err != nil {
+			Logc(ctx).WithField("volume", filePoolVolume).WithError(err).Error(
+				"Could not reconcile export policy.")
+			reconcileErrors = append(reconcileErrors, err.Error())
+		}
+	}
+
+	if len(reconcileErrors) > 0 {
+		return fmt.Errorf("unable to reconcile export policies; %s", strings.Join(reconcileErrors, "; "))
+	}
+
+	return nil
+}
+
+// getDesiredAllowedClients returns the sorted, comma-separated list of node addresses that match the
+// autoExportCIDRs, suitable for the allowed clients of an export rule.
+func (d *NASBlockStorageDriver) getDesiredAllowedClients(ctx context.Context, nodes []*utils.Node) (string, error) {
+	clients := make([]string, 0)
+	for _, node := range nodes {
+		// Filter the IPs based on the CIDRs provided by user
+		filteredIPs, err := utils.FilterIPs(ctx, node.IPs, d.Config.AutoExportCIDRs)
+		if err != nil {
+			return "", err
+		}
+		for _, ip := range filteredIPs {
+			if !utils.SliceContainsString(clients, ip) {
+				clients = append(clients, ip)
+			}
+		}
+	}
+
+	sort.Strings(clients)
+
+	return strings.Join(clients, ","), nil
+}
+
+// reconcileFilePoolVolumeAccess replaces the export policy of a parent file pool volume with a single rule
+// that allows only the specified clients.  Parent volumes that also hold subvolumes not managed by Trident
+// are left unchanged, since restricting them could cut off access for other consumers.  With an empty storage
+// prefix, other subvolumes cannot be told apart from Trident's own, so no parent volume is changed.
+func (d *NASBlockStorageDriver) reconcileFilePoolVolumeAccess(
+	ctx context.Context, filePoolVolume, allowedClients string,
+) error {
+	if *d.Config.StoragePrefix == "" {
+		Logc(ctx).WithField("volume", filePoolVolume).Warning("Storage prefix is empty, so subvolumes not " +
+			"managed by Trident cannot be detected; export policy not updated.")
+		return nil
+	}
+
+	volume, err := d.getFilePoolVolume(ctx, filePoolVolume)
+	if err != nil {
+		return err
+	}
+
+	subvolumes, err := d.SDK.Subvolumes(ctx, []string{filePoolVolume})
+	if err != nil {
+		return fmt.Errorf("could not list subvolumes of file pool volume '%s'; %v", filePoolVolume, err)
+	}
+
+	for _, subvolume := range *subvolumes {
+		if !d.hasStoragePrefix(subvolume.Name) {
+			Logc(ctx).WithFields(LogFields{
+				"volume":    filePoolVolume,
+				"subvolume": subvolume.Name,
+			}).Warning("File pool volume is shared with subvolumes not managed by Trident; " +
+				"export policy not updated.")
+			return nil
+		}
+	}
+
+	exportPolicy := d.getDesiredExportPolicy(volume, allowedClients)
+	if reflect.DeepEqual(volume.ExportPolicy, *exportPolicy) {
+		Logc(ctx).WithField("volume", filePoolVolume).Debug("Export policy is current.")
+		return nil
+	}
+
+	if d.Config.DryRun {
+		Logc(ctx).WithFields(LogFields{
+			"volume":         filePoolVolume,
+			"allowedClients": allowedClients,
+		}).Info("Dry run; export policy not updated.")
+		return nil
+	}
+
+	if err = d.SDK.ModifyVolumeExportPolicy(ctx, volume, exportPolicy); err != nil {
+		return fmt.Errorf("could not modify export policy of file pool volume '%s'; %v", filePoolVolume, err)
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"volume":         filePoolVolume,
+		"allowedClients": allowedClients,
+	}).Info("Export policy updated.")
+
 	return nil
 }
 
+// getDesiredExportPolicy returns an export policy with a single rule allowing the specified clients.  The
+// access and protocol settings of the volume's existing first rule are kept, so that Kerberos and read-only
+// configurations are preserved; otherwise a read-write rule for the volume's NFS version is created.
+func (d *NASBlockStorageDriver) getDesiredExportPolicy(
+	volume *api.FileSystem, allowedClients string,
+) *api.ExportPolicy {
+	var rule api.ExportRule
+
+	if len(volume.ExportPolicy.Rules) > 0 {
+		rule = volume.ExportPolicy.Rules[0]
+	} else {
+		rule = api.ExportRule{
+			Nfsv3:         utils.SliceContainsString(volume.ProtocolTypes, api.ProtocolTypeNFSv3),
+			Nfsv41:        utils.SliceContainsString(volume.ProtocolTypes, api.ProtocolTypeNFSv41),
+			UnixReadWrite: true,
+		}
+	}
+
+	rule.RuleIndex = 1
+	rule.AllowedClients = allowedClients
+
+	return &api.ExportPolicy{Rules: []api.ExportRule{rule}}
+}
+
+// applyExportRules adds the export rules from the backend config to the export policy of each file pool volume.
+// Existing rules for other clients are kept, unless the backend is configured to manage the whole export policy,
+// in which case the policy is replaced with only the configured rules.  A policy that already has the rules is not
+// modified.  In dry-run mode, the policy that would have been applied is only logged.
+func (d *NASBlockStorageDriver) applyExportRules(ctx context.Context) error {
+	if d.Config.ExportRule == "" && d.Config.ExportReadOnlyRule == "" {
+		return nil
+	}
+
+	for _, filePoolVolume := range d.getAllFilePoolVolumes() {
+		volume, err := d.getFilePoolVolume(ctx, filePoolVolume)
+		if err != nil {
+			return err
+		}
+
+		exportPolicy := d.getConfiguredExportPolicy(volume)
+		if reflect.DeepEqual(volume.ExportPolicy, *exportPolicy) {
+			Logc(ctx).WithField("volume", filePoolVolume).Debug("Export policy is current.")
+			continue
+		}
+
+		if d.Config.DryRun {
+			Logc(ctx).WithFields(LogFields{
+				"volume": filePoolVolume,
+				"rules":  exportPolicy.Rules,
+			}).Info("Dry run; export policy not updated.")
+			continue
+		}
+
+		if err = d.SDK.ModifyVolumeExportPolicy(ctx, volume, exportPolicy); err != nil {
+			return fmt.Errorf("could not modify export policy of file pool volume '%s'; %v", filePoolVolume, err)
+		}
+
+		Logc(ctx).WithFields(LogFields{
+			"volume": filePoolVolume,
+			"rules":  len(exportPolicy.Rules),
+		}).Info("Export policy updated.")
+	}
+
+	return nil
+}
+
+// getConfiguredExportPolicy returns the export policy a file pool volume should have given the backend's export
+// rules.  The exportRule clients get read-write access and the exportReadOnlyRule clients read-only access, each
+// over the volume's NFS versions.  Unless the backend manages the whole export policy, the volume's existing rules
+// are kept, and a configured rule is only added if no existing rule names the same clients.
+func (d *NASBlockStorageDriver) getConfiguredExportPolicy(volume *api.FileSystem) *api.ExportPolicy {
+	configuredRules := make([]api.ExportRule, 0, 2)
+	for _, rule := range []struct {
+		allowedClients string
+		readOnly       bool
+	}{
+		{d.Config.ExportRule, false},
+		{d.Config.ExportReadOnlyRule, true},
+	} {
+		if rule.allowedClients == "" {
+			continue
+		}
+		configuredRules = append(configuredRules, api.ExportRule{
+			AllowedClients: rule.allowedClients,
+			Nfsv3:          utils.SliceContainsString(volume.ProtocolTypes, api.ProtocolTypeNFSv3),
+			Nfsv41:         utils.SliceContainsString(volume.ProtocolTypes, api.ProtocolTypeNFSv41),
+			UnixReadOnly:   rule.readOnly,
+			UnixReadWrite:  !rule.readOnly,
+			RootSquash:     d.Config.ExportRootSquash,
+		})
+	}
+
+	rules := make([]api.ExportRule, 0, len(volume.ExportPolicy.Rules)+len(configuredRules))
+	if !d.Config.ManageExportPolicy {
+		rules = append(rules, volume.ExportPolicy.Rules...)
+	}
+
+	nextRuleIndex := int32(1)
+	for _, rule := range rules {
+		if rule.RuleIndex >= nextRuleIndex {
+			nextRuleIndex = rule.RuleIndex + 1
+		}
+	}
+
+	existingRules := len(rules)
+	for _, configuredRule := range configuredRules {
+		found := false
+		for _, rule := range rules[:existingRules] {
+			if rule.AllowedClients == configuredRule.AllowedClients {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		configuredRule.RuleIndex = nextRuleIndex
+		nextRuleIndex++
+		rules = append(rules, configuredRule)
+	}
+
+	return &api.ExportPolicy{Rules: rules}
+}
+
+// CheckHealth verifies that the backend can still reach Azure with its credentials by reading one of its file pool
+// volumes, bypassing any cache.  The check is cheap, so a backend that has lost its credentials or connectivity may
+// be reported as degraded before a volume operation fails.
+func (d *NASBlockStorageDriver) CheckHealth(ctx context.Context) error {
+	fields := LogFields{"Method": "CheckHealth", "Type": "NASBlockStorageDriver"}
+	Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace(">>>> CheckHealth")
+	defer Logd(ctx, d.Name(), d.Config.DebugTraceFlags["method"]).WithFields(fields).Trace("<<<< CheckHealth")
+
+	filePoolVolumes := d.getAllFilePoolVolumes()
+	if len(filePoolVolumes) == 0 {
+		return errors.New("backend has no file pool volumes to check")
+	}
+	filePoolVolume := filePoolVolumes[0]
+
+	resourceGroup, netappAccount, cPoolName, volumeName, err := api.ParseVolumeName(filePoolVolume)
+	if err != nil {
+		return fmt.Errorf("error parsing file pool volume name '%s'; %v", filePoolVolume, err)
+	}
+
+	_, err = d.SDK.VolumeByID(ctx, api.CreateVolumeID(d.Config.SubscriptionID, resourceGroup, netappAccount,
+		cPoolName, volumeName))
+	switch {
+	case err == nil:
+		return nil
+	case api.IsANFAuthorizationError(err):
+		return fmt.Errorf("backend credentials were rejected by Azure; check the client ID and secret or the "+
+			"workload or managed identity, and its role assignments; %v", err)
+	case errors.IsNotFoundError(err):
+		return fmt.Errorf("file pool volume '%s' no longer exists; %v", filePoolVolume, err)
+	default:
+		return fmt.Errorf("could not read file pool volume '%s' from Azure; %v", filePoolVolume, err)
+	}
+}
+
+// GetBackendState runs the health check each time the orchestrator polls the backend, and returns a reason if the
+// backend is unhealthy so that it is marked offline.  The file pool volumes are fixed by the backend config, so
+// the physical pools never change.
+func (d *NASBlockStorageDriver) GetBackendState(ctx context.Context) (string, *roaring.Bitmap) {
+	Logc(ctx).Debug(">>>> GetBackendState")
+	defer Logc(ctx).Debug("<<<< GetBackendState")
+
+	if err := d.CheckHealth(ctx); err != nil {
+		Logc(ctx).WithError(err).Warning("Backend health check failed.")
+		return StateReasonHealthCheckFailed, roaring.New()
+	}
+
+	return "", roaring.New()
+}
+
+// getFilePoolVolume reads a file pool volume by its name.
+func (d *NASBlockStorageDriver) getFilePoolVolume(ctx context.Context, filePoolVolume string) (*api.FileSystem, error) {
+	resourceGroup, netappAccount, cPoolName, volumeName, err := api.ParseVolumeName(filePoolVolume)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing file pool volume name '%s'; %v", filePoolVolume, err)
+	}
+
+	volume, err := d.SDK.VolumeByID(ctx, api.CreateVolumeID(d.Config.SubscriptionID, resourceGroup,
+		netappAccount, cPoolName, volumeName))
+	if err != nil {
+		return nil, fmt.Errorf("could not find file pool volume '%s'; %v", filePoolVolume, err)
+	}
+
+	// Refresh any cached copy so a changed set of mount targets is picked up by publish
+	d.cacheParentVolume(ctx, volume)
+
+	return volume, nil
+}
+
 // GetCommonConfig returns driver's CommonConfig
 func (d NASBlockStorageDriver) GetCommonConfig(context.Context) *drivers.CommonStorageDriverConfig {
 	return d.Config.CommonStorageDriverConfig
@@ -2038,8 +4514,10 @@ func (d *NASBlockStorageDriver) getAllFilePoolVolumes() []string {
 
 	if virtual {
 		for _, vpool := range d.virtualPools {
-			if !utils.SliceContainsString(candidateFileVolumePools, vpool.InternalAttributes()[FilePoolVolumes]) {
-				candidateFileVolumePools = append(candidateFileVolumePools, vpool.InternalAttributes()[FilePoolVolumes])
+			for _, filePoolVolume := range d.getPoolFilePoolVolumes(vpool) {
+				if !utils.SliceContainsString(candidateFileVolumePools, filePoolVolume) {
+					candidateFileVolumePools = append(candidateFileVolumePools, filePoolVolume)
+				}
 			}
 		}
 	} else {
@@ -2049,43 +4527,507 @@ func (d *NASBlockStorageDriver) getAllFilePoolVolumes() []string {
 	return candidateFileVolumePools
 }
 
+// getSecurityFlavors returns the security flavors listed in the last 'sec=' option of a mount options string.
+func getSecurityFlavors(mountOptions string) []string {
+	var flavors []string
+
+	for _, mountOption := range strings.Split(trimMountOptionsFlag(mountOptions), ",") {
+		mountOption = strings.TrimSpace(mountOption)
+		if strings.HasPrefix(mountOption, "sec=") {
+			flavors = strings.Split(strings.TrimPrefix(mountOption, "sec="), ":")
+		}
+	}
+
+	return flavors
+}
+
+// hasKerberosSecurityFlavor returns true if any of the security flavors use Kerberos.
+func hasKerberosSecurityFlavor(flavors []string) bool {
+	for _, flavor := range flavors {
+		if utils.SliceContainsString(kerberosSecurityFlavors, flavor) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSecurityFlavors ensures a parent volume can be mounted with the security flavors in the backend's
+// nfsMountOptions.  Kerberos requires a Kerberos-enabled NFSv4.1 volume, while 'sec=sys' requires an export rule
+// allowing Unix access.
+func validateSecurityFlavors(volume *api.FileSystem, flavors []string) error {
+	for _, flavor := range flavors {
+		if !utils.SliceContainsString(supportedSecurityFlavors, flavor) {
+			return fmt.Errorf("security flavor 'sec=%s' is not supported; must be one of %s", flavor,
+				strings.Join(supportedSecurityFlavors, ", "))
+		}
+
+		if utils.SliceContainsString(kerberosSecurityFlavors, flavor) {
+			if !volume.KerberosEnabled {
+				return fmt.Errorf("security flavor 'sec=%s' requires Kerberos, which is not enabled on volume %s",
+					flavor, volume.Name)
+			}
+			if !utils.SliceContainsString(volume.ProtocolTypes, api.ProtocolTypeNFSv41) {
+				return fmt.Errorf("security flavor 'sec=%s' requires NFSv4.1, which is not enabled on volume %s",
+					flavor, volume.Name)
+			}
+		} else if flavor == securityFlavorSys && volume.KerberosEnabled && len(volume.ExportPolicy.Rules) > 0 {
+			rule := volume.ExportPolicy.Rules[0]
+			if !rule.UnixReadWrite && !rule.UnixReadOnly {
+				return fmt.Errorf("security flavor 'sec=sys' is not allowed by the export policy of "+
+					"Kerberos-enabled volume %s", volume.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getPoolFilePoolVolumes returns the names of the file pool volumes backing a storage pool.
+func (d *NASBlockStorageDriver) getPoolFilePoolVolumes(pool storage.Pool) []string {
+	return strings.Split(pool.InternalAttributes()[FilePoolVolumes], ",")
+}
+
+// selectFilePoolVolume chooses the file pool volume in which to create a new subvolume.  When a pool has
+// more than one file pool volume, the one with the most free space is chosen, and ties are broken by
+// rotating through the volumes so that new subvolumes are spread across them.
+func (d *NASBlockStorageDriver) selectFilePoolVolume(
+	ctx context.Context, pool storage.Pool, sizeBytes uint64,
+) (string, error) {
+	candidates := d.getPoolFilePoolVolumes(pool)
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	filePoolVolumes, err := d.SDK.ValidateFilePoolVolumes(ctx, candidates)
+	if err != nil {
+		return "", fmt.Errorf("could not read file pool volumes for pool %s; %v", pool.Name(), err)
+	}
+	if len(filePoolVolumes) == 0 {
+		return "", fmt.Errorf("pool %s has no file pool volumes", pool.Name())
+	}
+
+	d.nextFilePoolVolumeLock.Lock()
+	start := d.nextFilePoolVolume % len(filePoolVolumes)
+	d.nextFilePoolVolume = start + 1
+	d.nextFilePoolVolumeLock.Unlock()
+
+	var selected *api.FileSystem
+	var selectedFreeBytes int64
+
+	for i := range filePoolVolumes {
+		filePoolVolume := filePoolVolumes[(start+i)%len(filePoolVolumes)]
+		freeBytes := filePoolVolume.QuotaInBytes - int64(filePoolVolume.UsedBytes)
+
+		Logc(ctx).WithFields(LogFields{
+			"volume":    filePoolVolume.FullName,
+			"freeBytes": freeBytes,
+		}).Trace("Considering file pool volume.")
+
+		if freeBytes < int64(sizeBytes) {
+			continue
+		}
+		if selected == nil || freeBytes > selectedFreeBytes {
+			selected = filePoolVolume
+			selectedFreeBytes = freeBytes
+		}
+	}
+
+	if selected == nil {
+		return "", fmt.Errorf("no file pool volume in pool %s has %d bytes available", pool.Name(), sizeBytes)
+	}
+
+	return selected.FullName, nil
+}
+
+// getSubvolumeParentVolume reads a subvolume's parent volume, retrying transient failures for a bounded time.
+// If the parent volume does not exist, that error is returned immediately, while an InProgressError is
+// returned if the lookup is still failing when the retry period expires so that the caller may retry later.
+func (d *NASBlockStorageDriver) getSubvolumeParentVolume(
+	ctx context.Context, volConfig *storage.VolumeConfig,
+) (*api.FileSystem, error) {
+	creationToken := volConfig.InternalName
+
+	if volume, ok := d.getCachedParentVolume(volConfig); ok {
+		return volume, nil
+	}
+
+	var volume *api.FileSystem
+
+	lookupParentVolume := func() error {
+		var err error
+		if volume, err = d.SDK.SubvolumeParentVolume(ctx, volConfig); err != nil {
+			if errors.IsNotFoundError(err) {
+				return backoff.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	}
+	lookupNotify := func(err error, duration time.Duration) {
+		Logc(ctx).WithFields(LogFields{
+			"increment": duration.Truncate(10 * time.Millisecond),
+			"subvolume": creationToken,
+		}).WithError(err).Debug("Could not read parent volume, retrying.")
+	}
+
+	// A zero timeout disables retries
+	var lookupBackoff backoff.BackOff = &backoff.StopBackOff{}
+	if d.parentVolumeLookupTimeout > 0 {
+		exponentialBackoff := backoff.NewExponentialBackOff()
+		exponentialBackoff.InitialInterval = parentVolumeLookupInterval
+		exponentialBackoff.RandomizationFactor = 0.1
+		exponentialBackoff.Multiplier = 2
+		exponentialBackoff.MaxInterval = 2 * time.Second
+		exponentialBackoff.MaxElapsedTime = d.parentVolumeLookupTimeout
+		lookupBackoff = exponentialBackoff
+	}
+
+	if err := backoff.RetryNotify(lookupParentVolume, lookupBackoff, lookupNotify); err != nil {
+		if errors.IsNotFoundError(err) {
+			return nil, fmt.Errorf("could not find subvolume's ('%s') parent volume: %v", creationToken, err)
+		}
+		return nil, errors.InProgressError(fmt.Sprintf("could not read subvolume's ('%s') parent volume: %v",
+			creationToken, err))
+	}
+
+	d.cacheParentVolume(ctx, volume)
+
+	return volume, nil
+}
+
+// parentVolumeCacheEntry is a parent volume read from the SDK, along with the time after which it is stale.
+type parentVolumeCacheEntry struct {
+	volume  *api.FileSystem
+	expires time.Time
+}
+
+// getCachedParentVolume returns a subvolume's parent volume if it was read from the SDK recently.
+func (d *NASBlockStorageDriver) getCachedParentVolume(volConfig *storage.VolumeConfig) (*api.FileSystem, bool) {
+	if d.parentVolumeCacheAge <= 0 {
+		return nil, false
+	}
+
+	_, resourceGroup, _, netappAccount, cPoolName, volumeName, _, err := api.ParseSubvolumeID(volConfig.InternalID)
+	if err != nil {
+		return nil, false
+	}
+	fullName := api.CreateVolumeFullName(resourceGroup, netappAccount, cPoolName, volumeName)
+
+	d.parentVolumeCacheLock.Lock()
+	defer d.parentVolumeCacheLock.Unlock()
+
+	entry, ok := d.parentVolumeCache[fullName]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(d.parentVolumeCache, fullName)
+		return nil, false
+	}
+
+	return entry.volume, true
+}
+
+// cacheParentVolume saves a parent volume just read from the SDK.  Any cached copy is replaced, and if the
+// volume's mount target addresses have changed since it was cached, the change is logged.
+func (d *NASBlockStorageDriver) cacheParentVolume(ctx context.Context, volume *api.FileSystem) {
+	if d.parentVolumeCacheAge <= 0 || volume == nil {
+		return
+	}
+
+	d.parentVolumeCacheLock.Lock()
+	defer d.parentVolumeCacheLock.Unlock()
+
+	if d.parentVolumeCache == nil {
+		d.parentVolumeCache = make(map[string]*parentVolumeCacheEntry)
+	}
+
+	if entry, ok := d.parentVolumeCache[volume.FullName]; ok &&
+		!reflect.DeepEqual(getMountTargetIPAddresses(entry.volume), getMountTargetIPAddresses(volume)) {
+		Logc(ctx).WithFields(LogFields{
+			"volume":       volume.FullName,
+			"oldAddresses": getMountTargetIPAddresses(entry.volume),
+			"newAddresses": getMountTargetIPAddresses(volume),
+		}).Info("Parent volume mount targets changed; replacing cached volume.")
+	}
+
+	d.parentVolumeCache[volume.FullName] = &parentVolumeCacheEntry{
+		volume:  volume,
+		expires: time.Now().Add(d.parentVolumeCacheAge),
+	}
+}
+
+// getMountTargetIPAddresses returns the sorted IP addresses of a volume's mount targets.
+func getMountTargetIPAddresses(volume *api.FileSystem) []string {
+	addresses := make([]string, 0, len(volume.MountTargets))
+	for _, mountTarget := range volume.MountTargets {
+		addresses = append(addresses, mountTarget.IPAddress)
+	}
+	sort.Strings(addresses)
+
+	return addresses
+}
+
+// getNFSVersionMountOption returns the NFS version mount option matching the protocol of a subvolume's parent volume.
+func (d *NASBlockStorageDriver) getNFSVersionMountOption(volume *api.FileSystem) (string, error) {
+	if len(volume.ProtocolTypes) == 0 {
+		return "", fmt.Errorf("parent volume %s has no protocol types", volume.Name)
+	}
+
+	return fmt.Sprintf("vers=%s", strings.TrimPrefix(volume.ProtocolTypes[0], api.ProtocolTypeNFSPrefix)), nil
+}
+
 func (d *NASBlockStorageDriver) createFilePoolVolumePathHash(filePoolVolume *api.FileSystem) string {
 	// volume path for hash: subscriptionID/resourceGroup/netappAccount/capacityPool/volume
 	// This volume path is unique to a filePoolVolume across subscriptions
 	volumePath := fmt.Sprintf("%s/%s/%s/%s/%s", d.Config.SubscriptionID, filePoolVolume.ResourceGroup,
 		filePoolVolume.NetAppAccount, filePoolVolume.CapacityPool, filePoolVolume.Name)
+
+	// Changing this option changes the NFS unique ID of every subvolume, so it should only be set on new backends
+	if d.Config.HashFilePoolVolumeResourceID {
+		volumePath = d.getCanonicalFilePoolVolumeID(filePoolVolume)
+	}
+
 	sha256Hash := sha256.Sum256([]byte(volumePath))
 
 	return fmt.Sprintf("%032x", sha256Hash[:RequiredHashLength])
 }
 
-func (d *NASBlockStorageDriver) deleteSubvolume(subvolume *api.Subvolume) error {
-	poller, err := d.SDK.DeleteSubvolume(ctx, subvolume)
-	if err != nil {
-		if !errors.IsNotFoundError(err) {
-			return fmt.Errorf("error deleting snapshot %s; %v", subvolume.Name, err)
+// getCanonicalFilePoolVolumeID returns the Azure resource ID of a file pool volume in a canonical form.  Azure
+// resource IDs are case-insensitive, so the ID is lowercased and stripped of surrounding slashes, so that equivalent
+// IDs always hash the same.  If the SDK did not report the resource ID, it is built from the volume's components.
+func (d *NASBlockStorageDriver) getCanonicalFilePoolVolumeID(filePoolVolume *api.FileSystem) string {
+	resourceID := filePoolVolume.ID
+	if resourceID == "" {
+		resourceID = api.CreateVolumeID(d.Config.SubscriptionID, filePoolVolume.ResourceGroup,
+			filePoolVolume.NetAppAccount, filePoolVolume.CapacityPool, filePoolVolume.Name)
+	}
+
+	return strings.ToLower(strings.Trim(resourceID, "/"))
+}
+
+// dryRunCreate logs the subvolume create request that would have been sent to Azure if the backend were not
+// in dry-run mode.  It returns an error, so that Trident does not record a volume or snapshot that doesn't exist.
+func (d *NASBlockStorageDriver) dryRunCreate(
+	ctx context.Context, operation string, request *api.SubvolumeCreateRequest,
+) error {
+	Logc(ctx).WithFields(addSizeLogFields(LogFields{
+		"operation":     operation,
+		"creationToken": request.CreationToken,
+		"volume":        request.Volume,
+		"parent":        request.Parent,
+	}, "size", uint64(request.Size))).Info("Dry run; subvolume not created.")
+
+	return fmt.Errorf("dry run; subvolume %s not created", request.CreationToken)
+}
+
+// dryRunModify logs a change to an existing subvolume that would have been made if the backend were not in
+// dry-run mode, and returns an error so that Trident does not record the change.
+func (d *NASBlockStorageDriver) dryRunModify(ctx context.Context, operation, subvolumeName string) error {
+	Logc(ctx).WithFields(LogFields{
+		"operation": operation,
+		"subvolume": subvolumeName,
+	}).Info("Dry run; subvolume not modified.")
+
+	return fmt.Errorf("dry run; subvolume %s not modified", subvolumeName)
+}
+
+// dryRunDelete logs the subvolume that would have been deleted if the backend were not in dry-run mode, and
+// returns an error so that Trident does not forget a volume or snapshot that still exists.
+func (d *NASBlockStorageDriver) dryRunDelete(ctx context.Context, operation string, subvolume *api.Subvolume) error {
+	Logc(ctx).WithFields(LogFields{
+		"operation": operation,
+		"subvolume": subvolume.Name,
+		"id":        subvolume.ID,
+		"volume": api.CreateVolumeFullName(subvolume.ResourceGroup, subvolume.NetAppAccount,
+			subvolume.CapacityPool, subvolume.Volume),
+	}).Info("Dry run; subvolume not deleted.")
+
+	return fmt.Errorf("dry run; subvolume %s not deleted", subvolume.Name)
+}
+
+// deleteSubvolume deletes a subvolume and waits up to the specified timeout for the deletion to complete.  A delete
+// request rejected with a transient error is retried, with backoff, up to the configured retry count, while a
+// subvolume that is already gone is treated as deleted.
+func (d *NASBlockStorageDriver) deleteSubvolume(
+	ctx context.Context, subvolume *api.Subvolume, timeout time.Duration,
+) error {
+	var poller api.PollerResponse
+	alreadyDeleted := false
+	deleteBackoff := &retryAfterBackOff{}
+
+	requestDelete := func() error {
+		var err error
+		if poller, err = d.SDK.DeleteSubvolume(ctx, subvolume); err != nil {
+			if errors.IsNotFoundError(err) {
+				alreadyDeleted = true
+				return nil
+			}
+			if !isTransientDeleteError(err) {
+				return backoff.Permanent(err)
+			}
+			if delay, ok := api.GetANFRetryAfter(err); ok {
+				deleteBackoff.retryAfter = delay
+			}
+			return err
+		}
+		// The SDK reports a subvolume that is already gone, as when another delete won a race, without a poller
+		if poller == nil {
+			alreadyDeleted = true
 		}
+		return nil
+	}
+	deleteNotify := func(err error, duration time.Duration) {
+		Logc(ctx).WithFields(LogFields{
+			"increment": duration.Truncate(10 * time.Millisecond),
+			"subvolume": subvolume.Name,
+		}).WithError(err).Debug("Subvolume delete rejected, retrying.")
+	}
+
+	exponentialBackoff := backoff.NewExponentialBackOff()
+	exponentialBackoff.InitialInterval = deleteRetryInterval
+	exponentialBackoff.RandomizationFactor = 0.1
+	exponentialBackoff.Multiplier = 2
+	exponentialBackoff.MaxElapsedTime = 0
+	deleteBackoff.BackOff = backoff.WithMaxRetries(exponentialBackoff, uint64(d.deleteRetryCount))
+
+	if err := backoff.RetryNotify(requestDelete, deleteBackoff, deleteNotify); err != nil {
+		return fmt.Errorf("error deleting snapshot %s; %v", subvolume.Name, err)
+	}
+
+	if alreadyDeleted {
+		Logc(ctx).Debugf("Subvolume %s already deleted.", subvolume.Name)
+		return nil
 	}
 
 	Logc(ctx).Debugf("Subvolume %s deleted.", subvolume.Name)
 
-	// Wait for deletion to complete
-	state, err := d.SDK.WaitForSubvolumeState(ctx, subvolume, api.StateDeleted, []string{api.StateError},
-		d.defaultTimeout())
+	return d.waitForSubvolumeDelete(ctx, subvolume, poller, timeout)
+}
 
-	if err != nil && state == api.StateError {
+// waitForSubvolumeDelete waits up to the specified timeout for a requested subvolume delete to complete.  The
+// poller may be nil.
+func (d *NASBlockStorageDriver) waitForSubvolumeDelete(
+	ctx context.Context, subvolume *api.Subvolume, poller api.PollerResponse, timeout time.Duration,
+) error {
+	state, err := d.SDK.WaitForSubvolumeState(ctx, subvolume, api.StateDeleted, []string{api.StateError}, timeout)
+
+	if err != nil && state == api.StateError && poller != nil {
 		Logc(ctx).WithField("subvolume", subvolume.Name).Errorf("failed to delete volume: %v", poller.Result(ctx))
 	}
 
 	return err
 }
 
+// isTransientDeleteError reports whether a rejected subvolume delete may succeed if retried, as when the request
+// was throttled or conflicted with another operation on the subvolume or its parent volume.
+func isTransientDeleteError(err error) bool {
+	return api.IsANFTooManyRequestsError(err) || api.IsANFConflictError(err)
+}
+
+// retryAfterBackOff is a backoff policy that, after a throttled attempt, waits as long as Azure asked in its
+// Retry-After header, up to maxRetryAfterDelay, rather than the wrapped policy's own interval.  The wrapped policy
+// still decides when to stop retrying.
+type retryAfterBackOff struct {
+	backoff.BackOff
+	retryAfter time.Duration
+}
+
+// NextBackOff returns the delay requested by the last throttled attempt, if any, or else the wrapped policy's next
+// interval.
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next == backoff.Stop || b.retryAfter == 0 {
+		return next
+	}
+
+	next = b.retryAfter
+	if next > maxRetryAfterDelay {
+		next = maxRetryAfterDelay
+	}
+	b.retryAfter = 0
+
+	return next
+}
+
+// cleanupOrphanedTempSubvolumes deletes temporary subvolumes left behind by snapshot restores that were
+// interrupted before they could clean up.  A temporary subvolume is only deleted if its primary subvolume exists and
+// is available, so the temporary copy is never the only copy of a subvolume's data, and if it is older than the
+// configured cleanup age, which should be far longer than any restore takes.  Failures are logged rather than
+// returned.
+func (d *NASBlockStorageDriver) cleanupOrphanedTempSubvolumes(ctx context.Context) {
+	if d.tempSubvolumeCleanupAge == 0 {
+		return
+	}
+
+	// Without a storage prefix, another consumer's subvolume could look like a temporary subvolume
+	if *d.Config.StoragePrefix == "" {
+		Logc(ctx).Warning("Storage prefix is empty; not cleaning up orphaned temporary subvolumes.")
+		return
+	}
+
+	for _, filePoolVolume := range d.getAllFilePoolVolumes() {
+		subvolumes, err := d.SDK.Subvolumes(ctx, []string{filePoolVolume})
+		if err != nil {
+			Logc(ctx).WithField("volume", filePoolVolume).WithError(err).Warning(
+				"Could not list subvolumes to clean up temporary subvolumes.")
+			continue
+		}
+
+		subvolumesByName := make(map[string]*api.Subvolume, len(*subvolumes))
+		for _, subvolume := range *subvolumes {
+			subvolumesByName[subvolume.Name] = subvolume
+		}
+
+		for _, tempSubvolume := range *subvolumes {
+			if !d.hasStoragePrefix(tempSubvolume.Name) || !strings.HasSuffix(tempSubvolume.Name, tempCopySuffix) {
+				continue
+			}
+
+			primaryName := strings.TrimSuffix(tempSubvolume.Name, tempCopySuffix)
+			logFields := LogFields{"subvolume": tempSubvolume.Name, "primary": primaryName}
+
+			primary, ok := subvolumesByName[primaryName]
+			if !ok || primary.ProvisioningState != api.StateAvailable {
+				Logc(ctx).WithFields(logFields).Warning(
+					"Temporary subvolume has no available primary subvolume; not deleting.")
+				continue
+			}
+
+			tempSubvolumeWithMetadata, err := d.SDK.SubvolumeByID(ctx, tempSubvolume.ID, true)
+			if err != nil {
+				Logc(ctx).WithFields(logFields).WithError(err).Warning(
+					"Could not read temporary subvolume metadata; not deleting.")
+				continue
+			}
+			if tempSubvolumeWithMetadata.Created.IsZero() ||
+				time.Since(tempSubvolumeWithMetadata.Created) < d.tempSubvolumeCleanupAge {
+				continue
+			}
+
+			Logc(ctx).WithFields(logFields).Info("Deleting orphaned temporary subvolume.")
+
+			if err = d.volumeRateLimiters.Wait(ctx, filePoolVolume); err != nil {
+				Logc(ctx).WithFields(logFields).WithError(err).Warning("Could not delete orphaned temporary subvolume.")
+				return
+			}
+
+			if err = d.deleteSubvolume(ctx, tempSubvolume, d.deleteTimeout); err != nil {
+				Logc(ctx).WithFields(logFields).WithError(err).Warning("Could not delete orphaned temporary subvolume.")
+			}
+		}
+	}
+}
+
 func (d *NASBlockStorageDriver) ensureSubvolumeDelete(subvolumeID, snapshotID string) {
-	if subvolumesToDelete == nil {
-		subvolumesToDelete = make(map[string]string)
+	d.subvolumesToDeleteLock.Lock()
+	defer d.subvolumesToDeleteLock.Unlock()
+
+	if d.subvolumesToDelete == nil {
+		d.subvolumesToDelete = make(map[string]string)
 	}
 
-	subvolumesToDelete[subvolumeID] = snapshotID
+	d.subvolumesToDelete[subvolumeID] = snapshotID
 }
 
 func (d *NASBlockStorageDriver) deleteSubvolumeInSnapshotContext(
@@ -2094,11 +5036,11 @@ func (d *NASBlockStorageDriver) deleteSubvolumeInSnapshotContext(
 ) (bool, error) {
 	var deletedInCurrentSnapshotContext bool
 
-	if subvolumesToDelete == nil {
-		return deletedInCurrentSnapshotContext, nil
-	}
+	d.subvolumesToDeleteLock.Lock()
+	existingSnapshotID, ok := d.subvolumesToDelete[subvolumeID]
+	d.subvolumesToDeleteLock.Unlock()
 
-	if existingSnapshotID, ok := subvolumesToDelete[subvolumeID]; ok {
+	if ok {
 		// Subvolume deletion is needed
 		_, resourceGroup, _, netappAccount, cPoolName, volumeName, subvolumeName,
 			err := api.ParseSubvolumeID(subvolumeID)
@@ -2116,13 +5058,15 @@ func (d *NASBlockStorageDriver) deleteSubvolumeInSnapshotContext(
 			Name:          subvolumeName,
 		}
 
-		if err = d.deleteSubvolume(subvolume); err != nil {
+		if err = d.deleteSubvolume(ctx, subvolume, d.deleteTimeout); err != nil {
 			Logc(ctx).WithError(err).Errorf("Failed to delete the subvolume '%s'.", subvolumeName)
 			return deletedInCurrentSnapshotContext, errors.InProgressError(err.Error())
 		}
 
 		// Remove subvolumeID from list of subvolumes required deletion
-		delete(subvolumesToDelete, subvolumeID)
+		d.subvolumesToDeleteLock.Lock()
+		delete(d.subvolumesToDelete, subvolumeID)
+		d.subvolumesToDeleteLock.Unlock()
 
 		Logc(ctx).Debugf("Subvolume '%s' deleted.", subvolumeName)
 
@@ -0,0 +1,75 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package azure
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/netapp/trident/config"
+	"github.com/netapp/trident/utils/errors"
+)
+
+const (
+	metricStatusSuccess    = "success"
+	metricStatusFailure    = "failure"
+	metricStatusInProgress = "in_progress"
+)
+
+var (
+	subvolumeOpsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: config.OrchestratorName,
+			Subsystem: "azure",
+			Name:      "subvolume_ops_total",
+			Help:      "The total number of handled ANF subvolume operations",
+		},
+		[]string{"backend", "op", "status"},
+	)
+
+	subvolumeOpsDurationInMsSummary = promauto.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace:  config.OrchestratorName,
+			Subsystem:  "azure",
+			Name:       "subvolume_operation_duration_in_milliseconds",
+			Help:       "The duration of ANF subvolume operations",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		},
+		[]string{"backend", "op", "status"},
+	)
+
+	subvolumeCreateWaitDurationInMsSummary = promauto.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Namespace:  config.OrchestratorName,
+			Subsystem:  "azure",
+			Name:       "subvolume_create_wait_duration_in_milliseconds",
+			Help:       "The time spent waiting for ANF subvolumes to become available",
+			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
+		},
+		[]string{"backend", "op"},
+	)
+)
+
+// recordSubvolumeOperation records the outcome and duration of a subvolume operation begun at startTime.  Operations
+// that return because they are still running, and will be retried, are not counted as failures.
+func recordSubvolumeOperation(backend, op string, startTime time.Time, err error) {
+	status := metricStatusSuccess
+	if err != nil {
+		status = metricStatusFailure
+		if errors.IsVolumeCreatingError(err) || errors.IsInProgressError(err) {
+			status = metricStatusInProgress
+		}
+	}
+
+	subvolumeOpsTotal.WithLabelValues(backend, op, status).Inc()
+	subvolumeOpsDurationInMsSummary.WithLabelValues(backend, op, status).Observe(
+		float64(time.Since(startTime).Milliseconds()))
+}
+
+// recordSubvolumeCreateWait records the time spent waiting for a subvolume to become available.
+func recordSubvolumeCreateWait(backend, op string, startTime time.Time) {
+	subvolumeCreateWaitDurationInMsSummary.WithLabelValues(backend, op).Observe(
+		float64(time.Since(startTime).Milliseconds()))
+}
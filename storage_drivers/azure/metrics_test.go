@@ -0,0 +1,37 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package azure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/netapp/trident/utils/errors"
+)
+
+func TestRecordSubvolumeOperation(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status string
+	}{
+		{"Success", nil, metricStatusSuccess},
+		{"Failure", errFailed, metricStatusFailure},
+		{"VolumeCreating", errors.VolumeCreatingError("creating"), metricStatusInProgress},
+		{"InProgress", errors.InProgressError("restoring"), metricStatusInProgress},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			counter := subvolumeOpsTotal.WithLabelValues("metricsBackend", "test_op", test.status)
+			before := testutil.ToFloat64(counter)
+
+			recordSubvolumeOperation("metricsBackend", "test_op", time.Now(), test.err)
+
+			assert.Equal(t, before+1, testutil.ToFloat64(counter), "counter not incremented")
+		})
+	}
+}
@@ -36,5 +36,6 @@ const (
 	KeyType string = "type"
 
 	// Mount options managed by drivers
-	MountOptionNoUUID = "nouuid"
+	MountOptionNoUUID   = "nouuid"
+	MountOptionReadOnly = "ro"
 )
@@ -102,6 +102,15 @@ func (d *CommonStorageDriverConfig) SetBackendName(backendName string) {
 	d.BackendName = backendName
 }
 
+// BackendCapabilities describes the operations a backend supports, given its driver and configuration
+type BackendCapabilities struct {
+	Clones    bool `json:"clones"`
+	Snapshots bool `json:"snapshots"`
+	Resize    bool `json:"resize"`
+	Shrink    bool `json:"shrink"`
+	Import    bool `json:"import"`
+}
+
 // OntapStorageDriverConfig holds settings for OntapStorageDrivers
 type OntapStorageDriverConfig struct {
 	*CommonStorageDriverConfig                  // embedded types replicate all fields
@@ -444,15 +453,56 @@ func (d SolidfireStorageDriverConfig) SpecOnlyValidation() error {
 
 type AzureNASStorageDriverConfig struct {
 	*CommonStorageDriverConfig
-	SubscriptionID      string `json:"subscriptionID"`
-	TenantID            string `json:"tenantID"`
-	ClientID            string `json:"clientID"`
-	ClientSecret        string `json:"clientSecret"`
-	Location            string `json:"location"`
-	NfsMountOptions     string `json:"nfsMountOptions"`
-	VolumeCreateTimeout string `json:"volumeCreateTimeout"`
-	SDKTimeout          string `json:"sdkTimeout"`
-	MaxCacheAge         string `json:"maxCacheAge"`
+	SubscriptionID                  string   `json:"subscriptionID"`
+	TenantID                        string   `json:"tenantID"`
+	ClientID                        string   `json:"clientID"`
+	ClientSecret                    string   `json:"clientSecret"`
+	Location                        string   `json:"location"`
+	CloudEnvironment                string   `json:"cloudEnvironment"`
+	NfsMountOptions                 string   `json:"nfsMountOptions"`
+	VolumeCreateTimeout             string   `json:"volumeCreateTimeout"`
+	DeleteTimeout                   string   `json:"deleteTimeout"`
+	ResizeTimeout                   string   `json:"resizeTimeout"`
+	SnapshotTimeout                 string   `json:"snapshotTimeout"`
+	ParentVolumeLookupTimeout       string   `json:"parentVolumeLookupTimeout"`
+	SDKTimeout                      string   `json:"sdkTimeout"`
+	MaxCacheAge                     string   `json:"maxCacheAge"`
+	ValidateMountTargetReachability bool     `json:"validateMountTargetReachability"`
+	MaxSubvolumesListed             string   `json:"maxSubvolumesListed"`
+	PollInterval                    string   `json:"pollInterval"`
+	MaxPollInterval                 string   `json:"maxPollInterval"`
+	AutoExportPolicy                bool     `json:"autoExportPolicy"`
+	AutoExportCIDRs                 []string `json:"autoExportCIDRs"`
+	AutoGrowParentVolume            bool     `json:"autoGrowParentVolume"`
+	RetainFailedVolumes             bool     `json:"retainFailedVolumes"`
+	MaxStoragePrefixLength          string   `json:"maxStoragePrefixLength"`
+	MaxVolumeSize                   string   `json:"maxVolumeSize"`
+	ClockSkewThreshold              string   `json:"clockSkewThreshold"`
+	UseLocalTimeOnClockSkew         bool     `json:"useLocalTimeOnClockSkew"`
+	AllowRenameOnImport             bool     `json:"allowRenameOnImport"`
+	TempSubvolumeCleanupAge         string   `json:"tempSubvolumeCleanupAge"`
+	HashFilePoolVolumeResourceID    bool     `json:"hashFilePoolVolumeResourceID"`
+	DryRun                          bool     `json:"dryRun"`
+	BackendNameSuffixLength         string   `json:"backendNameSuffixLength"`
+	DetailedSnapshotListing         bool     `json:"detailedSnapshotListing"`
+	DeleteRetryCount                string   `json:"deleteRetryCount"`
+	MinimumVolumeSize               string   `json:"minimumVolumeSize"`
+	SnapshotListingWorkers          string   `json:"snapshotListingWorkers"`
+	MaxSnapshotsPerVolume           string   `json:"maxSnapshotsPerVolume"`
+	DetectNameCollisions            bool     `json:"detectNameCollisions"`
+	ProxyURL                        string   `json:"proxyURL"`
+	RejectOverlappingPools          bool     `json:"rejectOverlappingPools"`
+	NameTemplate                    string   `json:"nameTemplate"`
+	RejectServiceLevelMismatch      bool     `json:"rejectServiceLevelMismatch"`
+	ExportReadOnlyRule              string   `json:"exportReadOnlyRule"`
+	ExportRootSquash                bool     `json:"exportRootSquash"`
+	ManageExportPolicy              bool     `json:"manageExportPolicy"`
+	ForceDestroy                    bool     `json:"forceDestroy"`
+	MaxOpsPerSecondPerVolume        string   `json:"maxOpsPerSecondPerVolume"`
+	HashedSnapshotSuffix            bool     `json:"hashedSnapshotSuffix"`
+	RejectExistingSnapshots         bool     `json:"rejectExistingSnapshots"`
+	StrictProtocolMatch             bool     `json:"strictProtocolMatch"`
+	UserAgentSuffix                 string   `json:"userAgentSuffix"`
 	AzureNASStorageDriverPool
 	Storage []AzureNASStorageDriverPool `json:"storage"`
 }
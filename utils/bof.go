@@ -10,6 +10,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -94,6 +95,9 @@ func AttachBlockOnFileVolume(
 		}
 	}
 
+	// Set only when a new filesystem is created, so any requested permissions are applied to it just once
+	formatted := false
+
 	if fsType != fsRaw {
 		err = ensureDeviceReadableWithRetry(ctx, loopDevice.Name)
 		if err != nil {
@@ -119,6 +123,7 @@ func AttachBlockOnFileVolume(
 			if err != nil {
 				return "", "", fmt.Errorf("error formatting device %s: %v", loopDevice.Name, err)
 			}
+			formatted = true
 		} else if existingFstype != unknownFstype && existingFstype != fsType {
 			Logc(ctx).WithFields(LogFields{
 				"device":          loopDevice.Name,
@@ -165,11 +170,53 @@ func AttachBlockOnFileVolume(
 			return "", "", fmt.Errorf("error mounting device %v, mountpoint %v; %s",
 				loopDevice.Name, deviceMountpoint, err)
 		}
+
+		// Leave existing filesystems alone, as their owners may have changed the root directory's permissions
+		if formatted && publishInfo.SubvolumeUnixPermissions != "" {
+			if err = setMountpointUnixPermissions(ctx, deviceMountpoint,
+				publishInfo.SubvolumeUnixPermissions); err != nil {
+				return "", "", err
+			}
+		}
 	}
 
 	return loopDevice.Name, deviceMountpoint, nil
 }
 
+// setMountpointUnixPermissions applies an octal unix permissions string, such as "0755", to the root directory
+// of a mounted filesystem.
+func setMountpointUnixPermissions(ctx context.Context, mountpoint, unixPermissions string) error {
+	if err := ValidateOctalUnixPermissions(unixPermissions); err != nil {
+		return err
+	}
+	perms, err := strconv.ParseUint(unixPermissions, 8, 32)
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(perms).Perm()
+	if perms&0o4000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if perms&0o2000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if perms&0o1000 != 0 {
+		mode |= os.ModeSticky
+	}
+
+	Logc(ctx).WithFields(LogFields{
+		"mountpoint":      mountpoint,
+		"unixPermissions": unixPermissions,
+	}).Debug("Setting unix permissions on mountpoint.")
+
+	if err = os.Chmod(mountpoint, mode); err != nil {
+		return fmt.Errorf("error setting unix permissions %s on mountpoint %s; %v", unixPermissions, mountpoint, err)
+	}
+
+	return nil
+}
+
 func DetachBlockOnFileVolume(ctx context.Context, loopDevice, loopFile string) error {
 	GenerateRequestContextForLayer(ctx, LogLayerUtils)
 
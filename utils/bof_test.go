@@ -0,0 +1,51 @@
+// Copyright 2023 NetApp, Inc. All Rights Reserved.
+
+package utils
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMountpointUnixPermissions(t *testing.T) {
+	tests := []struct {
+		UnixPermissions string
+		Expected        os.FileMode
+	}{
+		{"0755", 0o755},
+		{"0700", 0o700},
+		{"1777", 0o777 | os.ModeSticky},
+		{"2770", 0o770 | os.ModeSetgid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.UnixPermissions, func(t *testing.T) {
+			mountpoint := t.TempDir()
+
+			err := setMountpointUnixPermissions(context.Background(), mountpoint, test.UnixPermissions)
+
+			assert.NoError(t, err, "could not set unix permissions")
+			info, err := os.Stat(mountpoint)
+			assert.NoError(t, err, "could not stat mountpoint")
+			assert.Equal(t, os.ModeDir|test.Expected, info.Mode(), "wrong mode")
+		})
+	}
+}
+
+func TestSetMountpointUnixPermissions_Invalid(t *testing.T) {
+	mountpoint := t.TempDir()
+
+	for _, perms := range []string{"", "755", "0789", "rwxr-xr-x"} {
+		err := setMountpointUnixPermissions(context.Background(), mountpoint, perms)
+		assert.Error(t, err, "unix permissions %s should be invalid", perms)
+	}
+}
+
+func TestSetMountpointUnixPermissions_MissingMountpoint(t *testing.T) {
+	err := setMountpointUnixPermissions(context.Background(), "/nonexistent/trident/mountpoint", "0755")
+
+	assert.Error(t, err, "chmod of a missing mountpoint should fail")
+}
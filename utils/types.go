@@ -68,9 +68,10 @@ type SMBAccessInfo struct {
 }
 
 type NfsBlockAccessInfo struct {
-	SubvolumeName         string `json:"subvolumeName,omitempty"`
-	SubvolumeMountOptions string `json:"subvolumeMountOptions,omitempty"`
-	NFSMountpoint         string `json:"nfsMountpoint,omitempty"`
+	SubvolumeName            string `json:"subvolumeName,omitempty"`
+	SubvolumeMountOptions    string `json:"subvolumeMountOptions,omitempty"`
+	SubvolumeUnixPermissions string `json:"subvolumeUnixPermissions,omitempty"`
+	NFSMountpoint            string `json:"nfsMountpoint,omitempty"`
 }
 
 type NVMeAccessInfo struct {